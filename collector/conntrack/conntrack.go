@@ -0,0 +1,128 @@
+package conntrack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// Archivos de netfilter relativos a la raíz de procfs
+const (
+	countFile = "sys/net/netfilter/nf_conntrack_count"
+	maxFile   = "sys/net/netfilter/nf_conntrack_max"
+)
+
+// ConntrackMetrics contiene el uso de la tabla conntrack de netfilter
+type ConntrackMetrics struct {
+	Count              uint64  `json:"count"`
+	Max                uint64  `json:"max"`
+	UtilizationPercent float64 `json:"utilization_percent"`
+}
+
+// ConntrackCollector implementa la interfaz Collector para la tabla conntrack (solo Linux)
+type ConntrackCollector struct {
+	countPath string
+	maxPath   string
+	interval  time.Duration
+	log       *logrus.Entry
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "conntrack",
+		Enabled: func(cfg *config.Config) bool { return cfg.Conntrack != nil && cfg.Conntrack.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewConntrackCollector(cfg.Conntrack)
+		},
+	})
+}
+
+// NewConntrackCollector crea una nueva instancia de ConntrackCollector.
+// Devuelve un error si los archivos no existen (no es Linux o el módulo nf_conntrack no está cargado).
+func NewConntrackCollector(cfg *config.ConntrackConfig) (*ConntrackCollector, error) {
+	procPath := cfg.ProcPath
+	if procPath == "" {
+		procPath = "/proc"
+	}
+
+	c := &ConntrackCollector{
+		countPath: filepath.Join(procPath, countFile),
+		maxPath:   filepath.Join(procPath, maxFile),
+		interval:  time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:       logrus.WithField("collector", "conntrack"),
+	}
+
+	for _, path := range []string{c.countPath, c.maxPath} {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("conntrack no disponible (¿módulo nf_conntrack no cargado?): %w", err)
+		}
+	}
+	return c, nil
+}
+
+// readUint lee un único entero de un archivo de procfs
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("error al leer %s: %w", path, err)
+	}
+	val, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido en %s: %w", path, err)
+	}
+	return val, nil
+}
+
+// Collect recolecta el uso actual de la tabla conntrack
+func (c *ConntrackCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	count, err := readUint(c.countPath)
+	if err != nil {
+		return nil, err
+	}
+	max, err := readUint(c.maxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &ConntrackMetrics{Count: count, Max: max}
+	if max > 0 {
+		metrics.UtilizationPercent = float64(count) / float64(max) * 100
+	}
+
+	c.log.WithField("utilization_percent", metrics.UtilizationPercent).Debug("Métricas de conntrack recolectadas")
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *ConntrackCollector) Name() string {
+	return "conntrack"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *ConntrackCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *ConntrackCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *ConntrackCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "count", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Entradas actuales en la tabla conntrack."},
+		{Name: "max", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "utilization_percent", Type: collector.Gauge, Unit: collector.UnitPercent},
+	}
+}
@@ -0,0 +1,213 @@
+package cri
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// crictlTimeout limita la duración de cada invocación de crictl
+const crictlTimeout = 15 * time.Second
+
+// sandboxReady es el estado de un pod cuyo sandbox está en ejecución
+const sandboxReady = "SANDBOX_READY"
+
+// ContainerStats contiene el uso de recursos de un contenedor
+type ContainerStats struct {
+	Pod                   string  `json:"pod"`
+	Namespace             string  `json:"namespace"`
+	CPUPercent            float64 `json:"cpu_percent"` // Porcentaje de un núcleo desde la muestra anterior
+	MemoryWorkingSetBytes uint64  `json:"memory_working_set_bytes"`
+}
+
+// CRIMetrics contiene los pods y el uso de recursos de los contenedores del runtime
+type CRIMetrics struct {
+	PodsTotal  int                       `json:"pods_total"`
+	PodsReady  int                       `json:"pods_ready"`
+	Containers map[string]ContainerStats `json:"containers"` // Mapa por "namespace/pod/contenedor"
+}
+
+// protoUint64 acepta los uint64 de CRI tanto como número como como cadena (codificación JSON de protobuf)
+type protoUint64 uint64
+
+func (v *protoUint64) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseUint(string(bytes.Trim(data, `"`)), 10, 64)
+	if err != nil {
+		return err
+	}
+	*v = protoUint64(n)
+	return nil
+}
+
+// podsOutput es el subconjunto de `crictl pods -o json` que nos interesa
+type podsOutput struct {
+	Items []struct {
+		State string `json:"state"`
+	} `json:"items"`
+}
+
+// statsOutput es el subconjunto de `crictl stats -o json` que nos interesa
+type statsOutput struct {
+	Stats []struct {
+		Attributes struct {
+			ID       string `json:"id"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Labels map[string]string `json:"labels"`
+		} `json:"attributes"`
+		CPU struct {
+			UsageCoreNanoSeconds struct {
+				Value protoUint64 `json:"value"`
+			} `json:"usageCoreNanoSeconds"`
+		} `json:"cpu"`
+		Memory struct {
+			WorkingSetBytes struct {
+				Value protoUint64 `json:"value"`
+			} `json:"workingSetBytes"`
+		} `json:"memory"`
+	} `json:"stats"`
+}
+
+// CRICollector implementa la interfaz Collector para runtimes CRI (containerd, CRI-O) vía crictl
+type CRICollector struct {
+	crictlPath string
+	endpoint   string
+	interval   time.Duration
+	log        *logrus.Entry
+
+	cpuRates *collector.RateTracker // Tasa de la CPU acumulada (ns) por ID de contenedor
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "cri",
+		Enabled: func(cfg *config.Config) bool { return cfg.CRI != nil && cfg.CRI.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewCRICollector(cfg.CRI)
+		},
+	})
+}
+
+// NewCRICollector crea una nueva instancia de CRICollector.
+// Falla si el socket del runtime no existe (host sin CRI) o si crictl no está instalado.
+func NewCRICollector(cfg *config.CRIConfig) (*CRICollector, error) {
+	if _, err := os.Stat(cfg.SocketPath); err != nil {
+		return nil, fmt.Errorf("socket CRI no disponible en '%s': %w", cfg.SocketPath, err)
+	}
+
+	binary := cfg.CrictlPath
+	if binary == "" {
+		binary = "crictl"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("no se encontró crictl (%s): %w", binary, err)
+	}
+
+	return &CRICollector{
+		crictlPath: path,
+		endpoint:   "unix://" + cfg.SocketPath,
+		interval:   time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:        logrus.WithField("collector", "cri"),
+		cpuRates:   collector.NewRateTracker(logrus.WithField("collector", "cri")),
+	}, nil
+}
+
+// crictl ejecuta un subcomando con salida JSON y la decodifica en out
+func (c *CRICollector) crictl(ctx context.Context, out interface{}, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, crictlTimeout)
+	defer cancel()
+
+	args = append([]string{"--runtime-endpoint", c.endpoint}, args...)
+	data, err := exec.CommandContext(ctx, c.crictlPath, append(args, "-o", "json")...).Output()
+	if err != nil {
+		return fmt.Errorf("error al ejecutar crictl %s: %w", args[2], err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("error al parsear la salida de crictl %s: %w", args[2], err)
+	}
+	return nil
+}
+
+// Collect recolecta el estado de los pods y el uso de recursos de cada contenedor
+func (c *CRICollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	var pods podsOutput
+	if err := c.crictl(ctx, &pods, "pods"); err != nil {
+		return nil, err
+	}
+	var stats statsOutput
+	if err := c.crictl(ctx, &stats, "stats"); err != nil {
+		return nil, err
+	}
+
+	metrics := &CRIMetrics{
+		PodsTotal:  len(pods.Items),
+		Containers: make(map[string]ContainerStats, len(stats.Stats)),
+	}
+	for _, p := range pods.Items {
+		if p.State == sandboxReady {
+			metrics.PodsReady++
+		}
+	}
+
+	// Calcular porcentajes de CPU contra la muestra anterior (cero en la primera recolección)
+	now := time.Now()
+	for _, s := range stats.Stats {
+		attrs := s.Attributes
+		cpu := uint64(s.CPU.UsageCoreNanoSeconds.Value)
+
+		container := ContainerStats{
+			Pod:                   attrs.Labels["io.kubernetes.pod.name"],
+			Namespace:             attrs.Labels["io.kubernetes.pod.namespace"],
+			MemoryWorkingSetBytes: uint64(s.Memory.WorkingSetBytes.Value),
+		}
+		// Nanosegundos de CPU por segundo: 1e9 equivale al 100% de un núcleo
+		container.CPUPercent = c.cpuRates.Rate(attrs.ID, cpu, now) / 1e7
+		metrics.Containers[container.Namespace+"/"+container.Pod+"/"+attrs.Metadata.Name] = container
+	}
+	c.cpuRates.Sweep(now)
+
+	c.log.WithFields(logrus.Fields{
+		"pods":       metrics.PodsTotal,
+		"containers": len(metrics.Containers),
+	}).Debug("Métricas de CRI recolectadas")
+
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *CRICollector) Name() string {
+	return "cri"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *CRICollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *CRICollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *CRICollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "pods_total", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "pods_ready", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "cpu_percent", Type: collector.Gauge, Unit: collector.UnitPercent, Description: "Porcentaje de un núcleo usado por el contenedor."},
+		{Name: "memory_working_set_bytes", Type: collector.Gauge, Unit: collector.UnitBytes},
+	}
+}
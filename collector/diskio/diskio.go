@@ -0,0 +1,142 @@
+package diskio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/collector/filter"
+	"github.com/atrox39/logtick/config"
+)
+
+// Device contiene los contadores de E/S de un dispositivo de bloque y sus tasas por segundo.
+// Las tasas son cero en la primera recolección, ya que no hay muestra anterior.
+type Device struct {
+	ReadCount  uint64 `json:"read_count"`  // Lecturas completadas desde el arranque
+	WriteCount uint64 `json:"write_count"` // Escrituras completadas desde el arranque
+	ReadBytes  uint64 `json:"read_bytes"`
+	WriteBytes uint64 `json:"write_bytes"`
+	IOTimeMs   uint64 `json:"io_time_ms"` // Tiempo acumulado con E/S en curso
+
+	ReadsPerSecond      float64 `json:"reads_per_second"`
+	WritesPerSecond     float64 `json:"writes_per_second"`
+	ReadBytesPerSecond  float64 `json:"read_bytes_per_second"`
+	WriteBytesPerSecond float64 `json:"write_bytes_per_second"`
+	UtilizationPercent  float64 `json:"utilization_percent"` // Porcentaje del intervalo con E/S en curso, derivado de io_time_ms
+}
+
+// DiskIOMetrics contiene las métricas de E/S por dispositivo
+type DiskIOMetrics struct {
+	Devices map[string]Device `json:"devices"` // Mapa por nombre de dispositivo (ej. sda, nvme0n1)
+}
+
+// DiskIOCollector implementa la interfaz Collector para la E/S de disco
+type DiskIOCollector struct {
+	devices  *filter.Matcher // Dispositivos a reportar (vacío = todos)
+	interval time.Duration
+	log      *logrus.Entry
+
+	rates *collector.RateTracker // Tasas de los contadores, por dispositivo y contador
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "diskio",
+		Enabled: func(cfg *config.Config) bool { return cfg.DiskIO != nil && cfg.DiskIO.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewDiskIOCollector(cfg.DiskIO)
+		},
+	})
+}
+
+// NewDiskIOCollector crea una nueva instancia de DiskIOCollector.
+// Falla si devices contiene un patrón inválido o si el sistema no expone contadores de E/S de disco.
+func NewDiskIOCollector(cfg *config.DiskIOConfig) (*DiskIOCollector, error) {
+	devices, err := filter.Compile(cfg.Devices)
+	if err != nil {
+		return nil, fmt.Errorf("diskio.devices inválido: %w", err)
+	}
+	if _, err := disk.IOCounters(); err != nil {
+		return nil, fmt.Errorf("no se pudieron leer los contadores de E/S de disco: %w", err)
+	}
+	return &DiskIOCollector{
+		devices:  devices,
+		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:      logrus.WithField("collector", "diskio"),
+		rates:    collector.NewRateTracker(logrus.WithField("collector", "diskio")),
+	}, nil
+}
+
+// Collect lee los contadores de E/S de cada dispositivo y calcula las tasas desde la lectura anterior.
+// Se leen todos los dispositivos y se filtran aquí, ya que gopsutil solo acepta nombres exactos.
+func (c *DiskIOCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	counters, err := disk.IOCountersWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer los contadores de E/S de disco: %w", err)
+	}
+	now := time.Now()
+
+	metrics := &DiskIOMetrics{Devices: make(map[string]Device, len(counters))}
+	for name, io := range counters {
+		if !c.devices.Match(name) {
+			continue
+		}
+		d := Device{
+			ReadCount:  io.ReadCount,
+			WriteCount: io.WriteCount,
+			ReadBytes:  io.ReadBytes,
+			WriteBytes: io.WriteBytes,
+			IOTimeMs:   io.IoTime,
+		}
+		d.ReadsPerSecond = c.rates.Rate(name+"/read_count", io.ReadCount, now)
+		d.WritesPerSecond = c.rates.Rate(name+"/write_count", io.WriteCount, now)
+		d.ReadBytesPerSecond = c.rates.Rate(name+"/read_bytes", io.ReadBytes, now)
+		d.WriteBytesPerSecond = c.rates.Rate(name+"/write_bytes", io.WriteBytes, now)
+		// io_time está en milisegundos: ms por segundo / 10 = porcentaje del intervalo
+		d.UtilizationPercent = c.rates.Rate(name+"/io_time", io.IoTime, now) / 10
+		if d.UtilizationPercent > 100 {
+			d.UtilizationPercent = 100
+		}
+		metrics.Devices[name] = d
+	}
+	c.rates.Sweep(now)
+
+	c.log.WithField("devices", len(metrics.Devices)).Debug("Métricas de E/S de disco recolectadas")
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *DiskIOCollector) Name() string {
+	return "diskio"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *DiskIOCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *DiskIOCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *DiskIOCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "read_count", Type: collector.Counter, Unit: collector.UnitCount, Description: "Lecturas completadas por dispositivo."},
+		{Name: "write_count", Type: collector.Counter, Unit: collector.UnitCount, Description: "Escrituras completadas por dispositivo."},
+		{Name: "read_bytes", Type: collector.Counter, Unit: collector.UnitBytes},
+		{Name: "write_bytes", Type: collector.Counter, Unit: collector.UnitBytes},
+		{Name: "io_time_ms", Type: collector.Counter, Unit: collector.UnitNone, Description: "Milisegundos acumulados con E/S en curso."},
+		{Name: "reads_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "writes_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "read_bytes_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "write_bytes_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "utilization_percent", Type: collector.Gauge, Unit: collector.UnitPercent, Description: "Porcentaje del intervalo con E/S en curso."},
+	}
+}
@@ -0,0 +1,160 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// Resultados posibles de una resolución
+const (
+	statusOK       = "ok"
+	statusNXDomain = "nxdomain"
+	statusTimeout  = "timeout"
+	statusError    = "error"
+)
+
+// Resolution contiene el resultado de resolver un nombre
+type Resolution struct {
+	Success        bool    `json:"success"`
+	Status         string  `json:"status"`          // ok, nxdomain, timeout o error
+	LatencySeconds float64 `json:"latency_seconds"` // Tiempo hasta la respuesta o el fallo
+	Addresses      int     `json:"addresses"`       // Direcciones devueltas
+	Error          string  `json:"error,omitempty"`
+}
+
+// DNSMetrics contiene la resolución de cada nombre configurado
+type DNSMetrics struct {
+	Resolver string                `json:"resolver"` // "system" o la dirección configurada
+	Names    map[string]Resolution `json:"names"`    // Mapa por nombre de host
+}
+
+// DNSCollector implementa la interfaz Collector para la latencia de resolución DNS
+type DNSCollector struct {
+	resolver     *net.Resolver
+	resolverName string
+	hostnames    []string
+	timeout      time.Duration
+	interval     time.Duration
+	log          *logrus.Entry
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "dns",
+		Enabled: func(cfg *config.Config) bool { return cfg.DNS != nil && cfg.DNS.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewDNSCollector(cfg.DNS)
+		},
+	})
+}
+
+// NewDNSCollector crea una nueva instancia de DNSCollector.
+// Sin resolver configurado se usa el del sistema.
+func NewDNSCollector(cfg *config.DNSConfig) (*DNSCollector, error) {
+	if len(cfg.Hostnames) == 0 {
+		return nil, fmt.Errorf("se requiere al menos un nombre de host para el colector DNS")
+	}
+
+	c := &DNSCollector{
+		resolver:     net.DefaultResolver,
+		resolverName: "system",
+		hostnames:    cfg.Hostnames,
+		timeout:      time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		interval:     time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:          logrus.WithField("collector", "dns"),
+	}
+
+	if cfg.Resolver != "" {
+		address := cfg.Resolver
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, "53")
+		}
+		// Resolver de Go con todas las consultas dirigidas al servidor configurado
+		c.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, address)
+			},
+		}
+		c.resolverName = address
+	}
+	return c, nil
+}
+
+// Collect resuelve cada nombre y mide su latencia. Un fallo de resolución es un dato, no un error del colector.
+func (c *DNSCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	metrics := &DNSMetrics{
+		Resolver: c.resolverName,
+		Names:    make(map[string]Resolution, len(c.hostnames)),
+	}
+
+	for _, host := range c.hostnames {
+		metrics.Names[host] = c.resolve(ctx, host)
+	}
+
+	c.log.WithField("names", len(metrics.Names)).Debug("Métricas DNS recolectadas")
+	return metrics, nil
+}
+
+// resolve resuelve un nombre y clasifica el resultado
+func (c *DNSCollector) resolve(ctx context.Context, host string) Resolution {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	res := Resolution{LatencySeconds: time.Since(start).Seconds(), Addresses: len(addrs)}
+
+	if err == nil {
+		res.Success = true
+		res.Status = statusOK
+		return res
+	}
+
+	res.Error = err.Error()
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		res.Status = statusNXDomain
+	case errors.As(err, &dnsErr) && dnsErr.IsTimeout, errors.Is(err, context.DeadlineExceeded):
+		res.Status = statusTimeout
+	default:
+		res.Status = statusError
+	}
+	c.log.WithError(err).WithField("host", host).Debug("Fallo de resolución DNS")
+	return res
+}
+
+// Name devuelve el nombre de este colector
+func (c *DNSCollector) Name() string {
+	return "dns"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *DNSCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *DNSCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por cada nombre
+func (c *DNSCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "success", Type: collector.Gauge, Unit: collector.UnitNone, Description: "1 si el nombre se resolvió."},
+		{Name: "latency_seconds", Type: collector.Gauge, Unit: collector.UnitSeconds},
+		{Name: "addresses", Type: collector.Gauge, Unit: collector.UnitCount},
+	}
+}
@@ -0,0 +1,207 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector" // Importa el paquete collector para la interfaz
+	"github.com/atrox39/logtick/collector/httpclient"
+	"github.com/atrox39/logtick/config"
+)
+
+// NodeJVM contiene el uso de heap de un nodo
+type NodeJVM struct {
+	HeapUsedBytes   uint64 `json:"heap_used_bytes"`
+	HeapMaxBytes    uint64 `json:"heap_max_bytes"`
+	HeapUsedPercent uint64 `json:"heap_used_percent"`
+}
+
+// ElasticsearchMetrics contiene la salud del clúster y el uso de JVM por nodo
+type ElasticsearchMetrics struct {
+	ClusterName        string             `json:"cluster_name"`
+	Status             string             `json:"status"`      // green, yellow o red
+	StatusCode         int                `json:"status_code"` // 0 = green, 1 = yellow, 2 = red, -1 = desconocido
+	NumberOfNodes      uint64             `json:"number_of_nodes"`
+	ActiveShards       uint64             `json:"active_shards"`
+	RelocatingShards   uint64             `json:"relocating_shards"`
+	InitializingShards uint64             `json:"initializing_shards"`
+	UnassignedShards   uint64             `json:"unassigned_shards"`
+	Nodes              map[string]NodeJVM `json:"nodes"` // Mapa por nombre de nodo
+}
+
+// clusterHealth es la respuesta de GET /_cluster/health
+type clusterHealth struct {
+	ClusterName        string `json:"cluster_name"`
+	Status             string `json:"status"`
+	NumberOfNodes      uint64 `json:"number_of_nodes"`
+	ActiveShards       uint64 `json:"active_shards"`
+	RelocatingShards   uint64 `json:"relocating_shards"`
+	InitializingShards uint64 `json:"initializing_shards"`
+	UnassignedShards   uint64 `json:"unassigned_shards"`
+}
+
+// nodesStats es el subconjunto de GET /_nodes/stats/jvm que nos interesa
+type nodesStats struct {
+	Nodes map[string]struct {
+		Name string `json:"name"`
+		JVM  struct {
+			Mem struct {
+				HeapUsedInBytes uint64 `json:"heap_used_in_bytes"`
+				HeapMaxInBytes  uint64 `json:"heap_max_in_bytes"`
+				HeapUsedPercent uint64 `json:"heap_used_percent"`
+			} `json:"mem"`
+		} `json:"jvm"`
+	} `json:"nodes"`
+}
+
+// statusCodes convierte el estado textual del clúster en un valor numérico
+var statusCodes = map[string]int{"green": 0, "yellow": 1, "red": 2}
+
+// ElasticsearchCollector implementa la interfaz Collector para Elasticsearch/OpenSearch
+type ElasticsearchCollector struct {
+	client   *http.Client
+	baseURL  string
+	username string
+	password string
+	interval time.Duration
+	log      *logrus.Entry // Logger para este colector
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "elasticsearch",
+		Enabled: func(cfg *config.Config) bool { return cfg.Elasticsearch != nil && cfg.Elasticsearch.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewElasticsearchCollector(cfg.Elasticsearch)
+		},
+	})
+}
+
+// NewElasticsearchCollector crea una nueva instancia de ElasticsearchCollector
+func NewElasticsearchCollector(cfg *config.ElasticsearchConfig) (*ElasticsearchCollector, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("URL de Elasticsearch no puede estar vacía")
+	}
+	return &ElasticsearchCollector{
+		client:   httpclient.New(5 * time.Second),
+		baseURL:  strings.TrimRight(cfg.URL, "/"),
+		username: cfg.Username,
+		password: cfg.Password,
+		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:      logrus.WithField("collector", "elasticsearch"),
+	}, nil
+}
+
+// getJSON realiza un GET autenticado contra la API y decodifica la respuesta
+func (c *ElasticsearchCollector) getJSON(ctx context.Context, path string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("error al crear solicitud HTTP para Elasticsearch: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error al realizar solicitud HTTP a Elasticsearch '%s': %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("respuesta inesperada de Elasticsearch en '%s': %s", path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error al decodificar respuesta de Elasticsearch en '%s': %w", path, err)
+	}
+	return nil
+}
+
+// Collect recolecta la salud del clúster y el uso de heap por nodo
+func (c *ElasticsearchCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	var health clusterHealth
+	if err := c.getJSON(ctx, "/_cluster/health", &health); err != nil {
+		return nil, err
+	}
+
+	metrics := &ElasticsearchMetrics{
+		ClusterName:        health.ClusterName,
+		Status:             health.Status,
+		StatusCode:         -1,
+		NumberOfNodes:      health.NumberOfNodes,
+		ActiveShards:       health.ActiveShards,
+		RelocatingShards:   health.RelocatingShards,
+		InitializingShards: health.InitializingShards,
+		UnassignedShards:   health.UnassignedShards,
+		Nodes:              make(map[string]NodeJVM),
+	}
+	if code, ok := statusCodes[health.Status]; ok {
+		metrics.StatusCode = code
+	}
+
+	// Las estadísticas de nodos son complementarias: si fallan se reporta la salud igualmente
+	var stats nodesStats
+	if err := c.getJSON(ctx, "/_nodes/stats/jvm", &stats); err != nil {
+		c.log.WithError(err).Warn("No se pudieron obtener las estadísticas JVM de los nodos")
+	} else {
+		for id, node := range stats.Nodes {
+			name := node.Name
+			if name == "" {
+				name = id
+			}
+			metrics.Nodes[name] = NodeJVM{
+				HeapUsedBytes:   node.JVM.Mem.HeapUsedInBytes,
+				HeapMaxBytes:    node.JVM.Mem.HeapMaxInBytes,
+				HeapUsedPercent: node.JVM.Mem.HeapUsedPercent,
+			}
+		}
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"status":            metrics.Status,
+		"unassigned_shards": metrics.UnassignedShards,
+	}).Debug("Métricas de Elasticsearch recolectadas")
+
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *ElasticsearchCollector) Name() string {
+	return "elasticsearch"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *ElasticsearchCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *ElasticsearchCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *ElasticsearchCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "status_code", Type: collector.Gauge, Unit: collector.UnitNone, Description: "0 = green, 1 = yellow, 2 = red."},
+		{Name: "number_of_nodes", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "active_shards", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "relocating_shards", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "initializing_shards", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "unassigned_shards", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "heap_used_bytes", Type: collector.Gauge, Unit: collector.UnitBytes},
+		{Name: "heap_max_bytes", Type: collector.Gauge, Unit: collector.UnitBytes},
+		{Name: "heap_used_percent", Type: collector.Gauge, Unit: collector.UnitPercent},
+	}
+}
@@ -0,0 +1,73 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexPrefix marca un patrón como expresión regular en lugar de glob.
+// Ejemplo: "re:^(eth|ens)[0-9]+$"
+const regexPrefix = "re:"
+
+// Matcher es una lista de permitidos (allowlist) de nombres de dispositivos,
+// interfaces o puntos de montaje. Cada patrón puede ser un glob (ej. "eth*",
+// "/data/*") o una expresión regular con el prefijo "re:".
+// Un Matcher vacío o nil acepta cualquier nombre.
+type Matcher struct {
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// Compile valida y compila los patrones recibidos.
+// Devuelve un error descriptivo si algún glob o regex es inválido.
+func Compile(patterns []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		if strings.HasPrefix(p, regexPrefix) {
+			expr := strings.TrimPrefix(p, regexPrefix)
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("expresión regular inválida '%s': %w", expr, err)
+			}
+			m.regexps = append(m.regexps, re)
+			continue
+		}
+
+		// path.Match solo reporta patrones mal formados al evaluarlos
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("patrón glob inválido '%s': %w", p, err)
+		}
+		m.globs = append(m.globs, p)
+	}
+	return m, nil
+}
+
+// Empty indica si el Matcher no tiene patrones (y por tanto acepta todo).
+func (m *Matcher) Empty() bool {
+	return m == nil || (len(m.globs) == 0 && len(m.regexps) == 0)
+}
+
+// Match indica si el nombre coincide con alguno de los patrones.
+func (m *Matcher) Match(name string) bool {
+	if m.Empty() {
+		return true
+	}
+	for _, g := range m.globs {
+		if ok, _ := path.Match(g, name); ok {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package filter
+
+import "testing"
+
+func TestMatcher(t *testing.T) {
+	m, err := Compile([]string{"eth*", "/data/*", "re:^(sd|vd)[a-z]$", " "})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"eth0", true},
+		{"ens3", false},
+		{"/data/logs", true},
+		{"/data/logs/app", false}, // * no cruza separadores de ruta
+		{"/", false},
+		{"sda", true},
+		{"sda1", false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.name); got != tt.want {
+			t.Errorf("Match(%q) = %v, se esperaba %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEmptyMatcherAcceptsAll(t *testing.T) {
+	for _, patterns := range [][]string{nil, {}, {"", "  "}} {
+		m, err := Compile(patterns)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", patterns, err)
+		}
+		if !m.Empty() || !m.Match("cualquiera") {
+			t.Errorf("Compile(%q) debería aceptar cualquier nombre", patterns)
+		}
+	}
+	var m *Matcher
+	if !m.Match("cualquiera") {
+		t.Error("un Matcher nil debería aceptar cualquier nombre")
+	}
+}
+
+func TestCompileInvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"re:(eth", "eth["} {
+		if _, err := Compile([]string{pattern}); err == nil {
+			t.Errorf("Compile(%q) no devolvió error", pattern)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package haproxy
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector" // Importa el paquete collector para la interfaz
+	"github.com/atrox39/logtick/collector/httpclient"
+	"github.com/atrox39/logtick/config"
+)
+
+// rowTypes traduce la columna "type" del CSV de HAProxy
+var rowTypes = map[string]string{"0": "frontend", "1": "backend", "2": "server", "3": "listener"}
+
+// ProxyStats contiene las estadísticas de un frontend, backend o servidor
+type ProxyStats struct {
+	Type            string `json:"type"`   // frontend, backend, server o listener
+	Status          string `json:"status"` // UP, DOWN, OPEN, MAINT, ... tal como lo reporta HAProxy
+	Up              bool   `json:"up"`     // true si el estado es UP u OPEN
+	CurrentSessions uint64 `json:"current_sessions"`
+	MaxSessions     uint64 `json:"max_sessions"`
+	TotalSessions   uint64 `json:"total_sessions"`
+	QueueCurrent    uint64 `json:"queue_current"` // Solicitudes en cola (backends y servidores)
+}
+
+// HAProxyMetrics contiene las estadísticas por proxy de HAProxy
+type HAProxyMetrics struct {
+	Proxies map[string]ProxyStats `json:"proxies"` // Mapa por "proxy/servicio" (ej. "web/FRONTEND", "app/srv1")
+}
+
+// HAProxyCollector implementa la interfaz Collector para HAProxy
+type HAProxyCollector struct {
+	client   *http.Client
+	statsURL string
+	socket   string
+	username string
+	password string
+	interval time.Duration
+	log      *logrus.Entry // Logger para este colector
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "haproxy",
+		Enabled: func(cfg *config.Config) bool { return cfg.HAProxy != nil && cfg.HAProxy.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewHAProxyCollector(cfg.HAProxy)
+		},
+	})
+}
+
+// NewHAProxyCollector crea una nueva instancia de HAProxyCollector.
+// Si se configura stats_socket tiene prioridad sobre stats_url.
+func NewHAProxyCollector(cfg *config.HAProxyConfig) (*HAProxyCollector, error) {
+	if cfg.StatsURL == "" && cfg.StatsSocket == "" {
+		return nil, fmt.Errorf("se requiere stats_url o stats_socket para el colector de HAProxy")
+	}
+	statsURL := cfg.StatsURL
+	if statsURL != "" && !strings.HasSuffix(statsURL, ";csv") {
+		statsURL += ";csv"
+	}
+	return &HAProxyCollector{
+		client:   httpclient.New(5 * time.Second),
+		statsURL: statsURL,
+		socket:   cfg.StatsSocket,
+		username: cfg.Username,
+		password: cfg.Password,
+		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:      logrus.WithField("collector", "haproxy"),
+	}, nil
+}
+
+// fetchHTTP obtiene el CSV de estadísticas desde la página de stats
+func (c *HAProxyCollector) fetchHTTP(ctx context.Context) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.statsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear solicitud HTTP para HAProxy: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al realizar solicitud HTTP a HAProxy '%s': %w", c.statsURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta inesperada de HAProxy: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// fetchSocket obtiene el CSV de estadísticas con "show stat" en el socket de administración
+func (c *HAProxyCollector) fetchSocket() ([]byte, error) {
+	conn, err := net.DialTimeout("unix", c.socket, c.client.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error al conectar con el socket de HAProxy '%s': %w", c.socket, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.client.Timeout))
+
+	if _, err := conn.Write([]byte("show stat\n")); err != nil {
+		return nil, fmt.Errorf("error al escribir en el socket de HAProxy: %w", err)
+	}
+	return io.ReadAll(conn) // HAProxy cierra la conexión tras responder
+}
+
+// Collect recolecta las estadísticas de todos los proxies
+func (c *HAProxyCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	var data []byte
+	var err error
+	if c.socket != "" {
+		data, err = c.fetchSocket()
+	} else {
+		data, err = c.fetchHTTP(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := parseStats(data)
+	if err != nil {
+		return nil, err
+	}
+
+	c.log.WithField("proxies", len(metrics.Proxies)).Debug("Métricas de HAProxy recolectadas")
+	return metrics, nil
+}
+
+// parseStats interpreta el CSV de HAProxy localizando las columnas por nombre,
+// ya que su posición y número cambian entre versiones
+func parseStats(data []byte) (*HAProxyMetrics, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "# ")))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la cabecera del CSV de HAProxy: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	if _, ok := columns["pxname"]; !ok {
+		return nil, fmt.Errorf("el CSV de HAProxy no contiene la columna 'pxname'")
+	}
+
+	metrics := &HAProxyMetrics{Proxies: make(map[string]ProxyStats)}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error al leer el CSV de HAProxy: %w", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		number := func(name string) uint64 {
+			n, _ := strconv.ParseUint(field(name), 10, 64) // Vacío en filas donde no aplica
+			return n
+		}
+
+		status := field("status")
+		metrics.Proxies[field("pxname")+"/"+field("svname")] = ProxyStats{
+			Type:            rowTypes[field("type")],
+			Status:          status,
+			Up:              strings.HasPrefix(status, "UP") || status == "OPEN",
+			CurrentSessions: number("scur"),
+			MaxSessions:     number("smax"),
+			TotalSessions:   number("stot"),
+			QueueCurrent:    number("qcur"),
+		}
+	}
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *HAProxyCollector) Name() string {
+	return "haproxy"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *HAProxyCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *HAProxyCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por cada proxy
+func (c *HAProxyCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "up", Type: collector.Gauge, Unit: collector.UnitNone, Description: "1 si el estado es UP u OPEN."},
+		{Name: "current_sessions", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "max_sessions", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "total_sessions", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "queue_current", Type: collector.Gauge, Unit: collector.UnitCount},
+	}
+}
@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// HostInfo identifica la máquina en la que corre el agente. Se consulta una vez al arrancar;
+// solo el tiempo en marcha se recalcula en cada reporte.
+type HostInfo struct {
+	Hostname        string `json:"hostname"`
+	OS              string `json:"os"`                         // ej. linux, windows
+	Platform        string `json:"platform,omitempty"`         // ej. ubuntu
+	PlatformVersion string `json:"platform_version,omitempty"` // ej. 22.04
+	KernelVersion   string `json:"kernel_version,omitempty"`
+	UptimeSeconds   uint64 `json:"uptime_seconds,omitempty"`
+
+	bootTime uint64 // Arranque del sistema (Unix); 0 si no se pudo obtener
+}
+
+// NewHostInfo consulta la información del sistema. hostname sustituye al nombre del sistema si no
+// está vacío. Si la consulta falla se devuelven el nombre y el sistema operativo conocidos junto
+// con el error, para que el agente pueda seguir reportándolos.
+func NewHostInfo(hostname string) (*HostInfo, error) {
+	info, err := host.Info()
+	if err != nil || info == nil {
+		h := &HostInfo{Hostname: hostname, OS: runtime.GOOS}
+		if h.Hostname == "" {
+			h.Hostname, _ = os.Hostname()
+		}
+		return h, fmt.Errorf("error al obtener la información del sistema: %w", err)
+	}
+
+	h := &HostInfo{
+		Hostname:        info.Hostname,
+		OS:              info.OS,
+		Platform:        info.Platform,
+		PlatformVersion: info.PlatformVersion,
+		KernelVersion:   info.KernelVersion,
+		bootTime:        info.BootTime,
+	}
+	if hostname != "" {
+		h.Hostname = hostname
+	}
+	return h, nil
+}
+
+// At devuelve una copia con el tiempo en marcha calculado para now
+func (h *HostInfo) At(now time.Time) *HostInfo {
+	c := *h
+	if h.bootTime > 0 && uint64(now.Unix()) > h.bootTime {
+		c.UptimeSeconds = uint64(now.Unix()) - h.bootTime
+	}
+	return &c
+}
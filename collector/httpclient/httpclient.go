@@ -0,0 +1,55 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/atrox39/logtick/config"
+)
+
+var (
+	mu     sync.Mutex
+	shared *http.Transport // Transporte compartido por los colectores HTTP (nil = valores por defecto)
+)
+
+// Configure crea el transporte compartido con el ajuste de keepalive y conexiones inactivas.
+// Debe llamarse antes de inicializar los colectores; los campos a cero conservan los valores de Go.
+func Configure(cfg *config.HTTPClientConfig) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg != nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		if cfg.KeepAliveSeconds > 0 {
+			dialer.KeepAlive = time.Duration(cfg.KeepAliveSeconds) * time.Second
+		}
+		transport.DialContext = dialer.DialContext
+		if cfg.MaxIdleConns > 0 {
+			transport.MaxIdleConns = cfg.MaxIdleConns
+		}
+		if cfg.MaxIdleConnsPerHost > 0 {
+			transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		}
+		if cfg.IdleConnTimeoutSeconds > 0 {
+			transport.IdleConnTimeout = time.Duration(cfg.IdleConnTimeoutSeconds) * time.Second
+		}
+		transport.DisableKeepAlives = cfg.DisableKeepAlives
+	}
+
+	mu.Lock()
+	shared = transport
+	mu.Unlock()
+}
+
+// New devuelve un cliente con el timeout indicado sobre el transporte compartido,
+// de modo que las conexiones a los destinos sondeados se reutilizan entre recolecciones
+func New(timeout time.Duration) *http.Client {
+	mu.Lock()
+	if shared == nil {
+		shared = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport := shared
+	mu.Unlock()
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
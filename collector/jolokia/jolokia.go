@@ -0,0 +1,230 @@
+package jolokia
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector" // Importa el paquete collector para la interfaz
+	"github.com/atrox39/logtick/collector/httpclient"
+	"github.com/atrox39/logtick/config"
+)
+
+// defaultMBeans se consultan cuando la configuración no define selectores:
+// heap, recolectores de basura e hilos de la JVM
+var defaultMBeans = []config.JolokiaMBean{
+	{Name: "heap", MBean: "java.lang:type=Memory", Attributes: []string{"HeapMemoryUsage"}},
+	{Name: "gc", MBean: "java.lang:type=GarbageCollector,name=*", Attributes: []string{"CollectionCount", "CollectionTime"}},
+	{Name: "threads", MBean: "java.lang:type=Threading", Attributes: []string{"ThreadCount", "DaemonThreadCount", "PeakThreadCount"}},
+}
+
+// JolokiaMetrics contiene los valores numéricos leídos de los MBeans configurados.
+// Las claves se forman con el nombre del selector y la ruta dentro del valor, separadas por puntos
+// (ej. "heap.HeapMemoryUsage.used", "gc.G1 Young Generation.CollectionCount").
+type JolokiaMetrics struct {
+	Values map[string]float64 `json:"values"`
+}
+
+// readRequest es una operación "read" de la API bulk de Jolokia
+type readRequest struct {
+	Type      string      `json:"type"`
+	MBean     string      `json:"mbean"`
+	Attribute interface{} `json:"attribute,omitempty"` // string o lista de strings
+	Path      string      `json:"path,omitempty"`
+}
+
+// readResponse es la respuesta de Jolokia a una operación
+type readResponse struct {
+	Status int         `json:"status"`
+	Error  string      `json:"error"`
+	Value  interface{} `json:"value"`
+}
+
+// JolokiaCollector implementa la interfaz Collector para JVMs expuestas vía Jolokia (JMX sobre HTTP)
+type JolokiaCollector struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+	mbeans   []config.JolokiaMBean
+	interval time.Duration
+	log      *logrus.Entry // Logger para este colector
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "jolokia",
+		Enabled: func(cfg *config.Config) bool { return cfg.Jolokia != nil && cfg.Jolokia.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewJolokiaCollector(cfg.Jolokia)
+		},
+	})
+}
+
+// NewJolokiaCollector crea una nueva instancia de JolokiaCollector
+func NewJolokiaCollector(cfg *config.JolokiaConfig) (*JolokiaCollector, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("URL de Jolokia no puede estar vacía")
+	}
+	mbeans := cfg.MBeans
+	if len(mbeans) == 0 {
+		mbeans = defaultMBeans
+	}
+	for _, m := range mbeans {
+		if m.MBean == "" {
+			return nil, fmt.Errorf("cada selector de Jolokia requiere un 'mbean'")
+		}
+	}
+	return &JolokiaCollector{
+		client:   httpclient.New(5 * time.Second),
+		url:      cfg.URL,
+		username: cfg.Username,
+		password: cfg.Password,
+		mbeans:   mbeans,
+		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:      logrus.WithField("collector", "jolokia"),
+	}, nil
+}
+
+// Collect lee todos los selectores en una única petición bulk
+func (c *JolokiaCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	requests := make([]readRequest, len(c.mbeans))
+	for i, m := range c.mbeans {
+		requests[i] = readRequest{Type: "read", MBean: m.MBean, Path: m.Path}
+		switch len(m.Attributes) {
+		case 0: // Todos los atributos
+		case 1:
+			requests[i].Attribute = m.Attributes[0]
+		default:
+			requests[i].Attribute = m.Attributes
+		}
+	}
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar la solicitud a Jolokia: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error al crear solicitud HTTP para Jolokia: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al realizar solicitud HTTP a Jolokia '%s': %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta inesperada de Jolokia: %s", resp.Status)
+	}
+
+	var responses []readResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		return nil, fmt.Errorf("error al decodificar respuesta de Jolokia: %w", err)
+	}
+	if len(responses) != len(c.mbeans) {
+		return nil, fmt.Errorf("Jolokia devolvió %d respuestas para %d selectores", len(responses), len(c.mbeans))
+	}
+
+	metrics := &JolokiaMetrics{Values: make(map[string]float64)}
+	for i, r := range responses {
+		m := c.mbeans[i]
+		// Un MBean inexistente o un atributo inválido no invalida al resto
+		if r.Status != http.StatusOK {
+			c.log.WithField("mbean", m.MBean).Warnf("Jolokia respondió %d: %s", r.Status, r.Error)
+			continue
+		}
+		prefix := m.Name
+		if prefix == "" {
+			prefix = m.MBean
+		}
+		// Con comodines el valor es un mapa por nombre completo de MBean
+		if strings.Contains(m.MBean, "*") {
+			if byBean, ok := r.Value.(map[string]interface{}); ok {
+				for bean, v := range byBean {
+					flatten(prefix+"."+shortName(bean), v, metrics.Values)
+				}
+				continue
+			}
+		}
+		// Con un único atributo el valor es directamente el del atributo
+		if len(m.Attributes) == 1 {
+			prefix += "." + m.Attributes[0]
+		}
+		flatten(prefix, r.Value, metrics.Values)
+	}
+
+	c.log.WithField("values", len(metrics.Values)).Debug("Métricas de Jolokia recolectadas")
+	return metrics, nil
+}
+
+// flatten añade a out los valores numéricos de v, recorriendo mapas anidados
+func flatten(prefix string, v interface{}, out map[string]float64) {
+	switch val := v.(type) {
+	case float64:
+		out[prefix] = val
+	case bool:
+		if val {
+			out[prefix] = 1
+		} else {
+			out[prefix] = 0
+		}
+	case map[string]interface{}:
+		for k, nested := range val {
+			flatten(prefix+"."+k, nested, out)
+		}
+	}
+}
+
+// shortName extrae la propiedad "name" de un ObjectName JMX (ej. "java.lang:name=G1 Old Generation,type=GarbageCollector").
+// Si no existe devuelve el ObjectName completo.
+func shortName(objectName string) string {
+	_, props, found := strings.Cut(objectName, ":")
+	if !found {
+		return objectName
+	}
+	for _, prop := range strings.Split(props, ",") {
+		if key, value, ok := strings.Cut(prop, "="); ok && key == "name" {
+			return value
+		}
+	}
+	return objectName
+}
+
+// Name devuelve el nombre de este colector
+func (c *JolokiaCollector) Name() string {
+	return "jolokia"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *JolokiaCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *JolokiaCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector.
+// Los valores dependen de los MBeans configurados, por lo que se reportan como gauges sin unidad.
+func (c *JolokiaCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "values", Type: collector.Gauge, Unit: collector.UnitNone, Description: "Valores numéricos de los MBeans configurados."},
+	}
+}
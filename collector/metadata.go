@@ -0,0 +1,39 @@
+package collector
+
+// MetricType indica cómo debe interpretar el backend un valor.
+type MetricType string
+
+const (
+	Gauge   MetricType = "gauge"   // Valor instantáneo que puede subir o bajar
+	Counter MetricType = "counter" // Valor acumulado monótonamente creciente
+)
+
+// Unit es la unidad en la que se reporta un valor.
+type Unit string
+
+const (
+	UnitBytes     Unit = "bytes"
+	UnitKilobytes Unit = "kilobytes"
+	UnitMegabytes Unit = "megabytes"
+	UnitGigabytes Unit = "gigabytes"
+	UnitPercent   Unit = "percent"
+	UnitSeconds   Unit = "seconds"
+	UnitCelsius   Unit = "celsius"
+	UnitCount     Unit = "count"
+	UnitPerSecond Unit = "per_second"
+	UnitNone      Unit = "none"
+)
+
+// MetricDescriptor describe un campo de las métricas de un colector.
+// Name coincide con la etiqueta JSON del campo en el reporte.
+type MetricDescriptor struct {
+	Name        string     `json:"name"`
+	Type        MetricType `json:"type"`
+	Unit        Unit       `json:"unit"`
+	Description string     `json:"description,omitempty"`
+}
+
+// Describer es implementado por los colectores que publican metadatos de sus métricas.
+type Describer interface {
+	Metadata() []MetricDescriptor
+}
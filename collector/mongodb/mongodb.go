@@ -0,0 +1,210 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// queryTimeout limita la duración de cada comando administrativo
+const queryTimeout = 5 * time.Second
+
+// noReplicationEnabledCode es el código de error de replSetGetStatus en un servidor standalone
+const noReplicationEnabledCode = 76
+
+// MongoDBMetrics contiene las métricas específicas de MongoDB
+type MongoDBMetrics struct {
+	ConnectionsCurrent    int64              `json:"connections_current"`
+	ConnectionsAvailable  int64              `json:"connections_available"`
+	Opcounters            map[string]int64   `json:"opcounters_total"`                  // Acumulados desde el arranque
+	OpcountersPerSecond   map[string]float64 `json:"opcounters_per_second"`             // Calculados contra la muestra anterior
+	ReplicaState          string             `json:"replica_state,omitempty"`           // PRIMARY, SECONDARY, ... (vacío si no hay replica set)
+	ReplicationLagSeconds *float64           `json:"replication_lag_seconds,omitempty"` // Solo en secundarios
+}
+
+// MongoDBCollector implementa la interfaz Collector para métricas de MongoDB
+type MongoDBCollector struct {
+	client   *mongo.Client
+	interval time.Duration
+	log      *logrus.Entry
+
+	opcounterRates *collector.RateTracker // Tasas de los opcounters
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "mongodb",
+		Enabled: func(cfg *config.Config) bool { return cfg.MongoDB != nil && cfg.MongoDB.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewMongoDBCollector(cfg.MongoDB)
+		},
+	})
+}
+
+// NewMongoDBCollector crea una nueva instancia de MongoDBCollector.
+// La autenticación y TLS se configuran en la propia URI (ej. "?tls=true&authSource=admin").
+func NewMongoDBCollector(cfg *config.MongoDBConfig) (*MongoDBCollector, error) {
+	if cfg.URI == "" {
+		return nil, fmt.Errorf("URI de MongoDB no puede estar vacía")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
+	if err != nil {
+		return nil, fmt.Errorf("error al crear cliente MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("error al conectar con MongoDB: %w", err)
+	}
+
+	return &MongoDBCollector{
+		client:   client,
+		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:      logrus.WithField("collector", "mongodb"),
+
+		opcounterRates: collector.NewRateTracker(logrus.WithField("collector", "mongodb")),
+	}, nil
+}
+
+// Collect recolecta métricas de MongoDB
+func (c *MongoDBCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	admin := c.client.Database("admin")
+
+	var status bson.M
+	if err := admin.RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status); err != nil {
+		return nil, fmt.Errorf("error al ejecutar 'serverStatus': %w", err)
+	}
+
+	metrics := &MongoDBMetrics{
+		Opcounters:          make(map[string]int64),
+		OpcountersPerSecond: make(map[string]float64),
+	}
+
+	if conns, ok := status["connections"].(bson.M); ok {
+		metrics.ConnectionsCurrent = toInt64(conns["current"])
+		metrics.ConnectionsAvailable = toInt64(conns["available"])
+	}
+	if ops, ok := status["opcounters"].(bson.M); ok {
+		for op, v := range ops {
+			metrics.Opcounters[op] = toInt64(v)
+		}
+	}
+
+	// Calcular tasas a partir de la muestra anterior (cero en la primera recolección)
+	now := time.Now()
+	for op, v := range metrics.Opcounters {
+		if v >= 0 {
+			metrics.OpcountersPerSecond[op] = c.opcounterRates.Rate(op, uint64(v), now)
+		}
+	}
+
+	if err := c.collectReplication(ctx, metrics); err != nil {
+		c.log.WithError(err).Warn("No se pudo obtener el estado de replicación de MongoDB")
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"connections_current": metrics.ConnectionsCurrent,
+		"replica_state":       metrics.ReplicaState,
+	}).Debug("Métricas de MongoDB recolectadas")
+
+	return metrics, nil
+}
+
+// collectReplication obtiene el estado del replica set y calcula el retraso
+// del miembro actual respecto al primario. En un servidor standalone no hace nada.
+func (c *MongoDBCollector) collectReplication(ctx context.Context, metrics *MongoDBMetrics) error {
+	var rs bson.M
+	err := c.client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&rs)
+	if err != nil {
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Code == noReplicationEnabledCode {
+			return nil // No es un replica set
+		}
+		return err
+	}
+
+	members, _ := rs["members"].(bson.A)
+	var primaryOptime, selfOptime time.Time
+	for _, m := range members {
+		member, ok := m.(bson.M)
+		if !ok {
+			continue
+		}
+		optime, _ := member["optimeDate"].(primitive.DateTime)
+		if member["stateStr"] == "PRIMARY" {
+			primaryOptime = optime.Time()
+		}
+		if self, _ := member["self"].(bool); self {
+			selfOptime = optime.Time()
+			metrics.ReplicaState, _ = member["stateStr"].(string)
+		}
+	}
+
+	if metrics.ReplicaState == "SECONDARY" && !primaryOptime.IsZero() && !selfOptime.IsZero() {
+		lag := primaryOptime.Sub(selfOptime).Seconds()
+		if lag < 0 {
+			lag = 0
+		}
+		metrics.ReplicationLagSeconds = &lag
+	}
+	return nil
+}
+
+// toInt64 convierte los tipos numéricos que devuelve el driver a int64
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+// Name devuelve el nombre de este colector
+func (c *MongoDBCollector) Name() string {
+	return "mongodb"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *MongoDBCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close desconecta el cliente de MongoDB
+func (c *MongoDBCollector) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	return c.client.Disconnect(ctx)
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *MongoDBCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "connections_current", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "connections_available", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "opcounters_total", Type: collector.Counter, Unit: collector.UnitCount, Description: "Operaciones por tipo desde el arranque."},
+		{Name: "opcounters_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "replication_lag_seconds", Type: collector.Gauge, Unit: collector.UnitSeconds},
+	}
+}
@@ -2,18 +2,28 @@ package mysql
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql" // Driver de MySQL
+	"github.com/go-sql-driver/mysql"
 	"github.com/sirupsen/logrus"
 
 	"github.com/atrox39/logtick/collector" // Importa el paquete collector para la interfaz
 	"github.com/atrox39/logtick/config"
 )
 
+// Límites del pool de conexiones: el colector ejecuta sus consultas en secuencia, así que
+// pocas conexiones bastan, y renovarlas periódicamente evita usar conexiones cerradas por el servidor
+const (
+	maxOpenConns    = 2
+	connMaxLifetime = 5 * time.Minute
+)
+
 // MySQLMetrics contiene las métricas específicas de MySQL
 type MySQLMetrics struct {
 	Uptime               uint64  `json:"uptime_seconds"`
@@ -24,14 +34,98 @@ type MySQLMetrics struct {
 	BytesSent            uint64  `json:"bytes_sent"`
 	Queries              uint64  `json:"queries_total"`
 	InnodbBufferPoolHits float64 `json:"innodb_buffer_pool_reads_hits_ratio"`
+
+	// Tasas instantáneas medidas en la ventana corta de muestreo (solo con sample_window_ms > 0)
+	QueriesPerSecond       *float64 `json:"queries_per_second,omitempty"`
+	BytesReceivedPerSecond *float64 `json:"bytes_received_per_second,omitempty"`
+	BytesSentPerSecond     *float64 `json:"bytes_sent_per_second,omitempty"`
+
+	// Operaciones de filas de InnoDB: acumulados desde el arranque (_total) y tasas contra la ronda anterior (_per_second, cero en la primera)
+	InnodbRowsRead              uint64  `json:"innodb_rows_read_total"`
+	InnodbRowsInserted          uint64  `json:"innodb_rows_inserted_total"`
+	InnodbRowsUpdated           uint64  `json:"innodb_rows_updated_total"`
+	InnodbRowsDeleted           uint64  `json:"innodb_rows_deleted_total"`
+	InnodbRowsReadPerSecond     float64 `json:"innodb_rows_read_per_second"`
+	InnodbRowsInsertedPerSecond float64 `json:"innodb_rows_inserted_per_second"`
+	InnodbRowsUpdatedPerSecond  float64 `json:"innodb_rows_updated_per_second"`
+	InnodbRowsDeletedPerSecond  float64 `json:"innodb_rows_deleted_per_second"`
+
+	// Contención: consultas lentas (más de long_query_time) y bloqueos de tabla
+	SlowQueries          uint64   `json:"slow_queries_total"`
+	SlowQueriesPerSecond float64  `json:"slow_queries_per_second"` // Contra la ronda anterior, cero en la primera
+	TableLocksWaited     uint64   `json:"table_locks_waited_total"`
+	TableLocksImmediate  uint64   `json:"table_locks_immediate_total"`
+	LongQueryTime        *float64 `json:"long_query_time_seconds,omitempty"` // Umbral de consulta lenta; se omite si no se pudo leer
+
+	// Tamaño en disco (datos + índices) de cada base de datos; solo con collect_db_sizes. Se consulta
+	// cada db_sizes_interval_seconds y entre tanto se repite la última medición.
+	DatabaseSizes map[string]uint64 `json:"database_size_bytes,omitempty"`
+
+	// Replicación (solo con collect_replication y si el servidor es una réplica; se omiten en primarios)
+	SecondsBehindSource *uint64 `json:"seconds_behind_source,omitempty"` // Ausente también si MySQL lo reporta NULL (hilo SQL detenido)
+	IORunning           *bool   `json:"io_running,omitempty"`
+	SQLRunning          *bool   `json:"sql_running,omitempty"`
 }
 
 // MySQLCollector implementa la interfaz Collector para métricas de MySQL
 type MySQLCollector struct {
-	db       *sql.DB
-	dsn      string
-	interval time.Duration
-	log      *logrus.Entry // Logger para este colector
+	db           *sql.DB
+	dsn          string
+	interval     time.Duration
+	sampleWindow time.Duration // Separación entre las dos muestras de una ronda (0 = una sola muestra)
+	queryTimeout time.Duration // Tiempo máximo de cada consulta
+	log          *logrus.Entry // Logger para este colector
+
+	counterRates *collector.RateTracker // Tasas entre rondas de rateCounters
+
+	collectReplication bool   // Consultar el estado de replicación en cada ronda
+	replicaStatement   string // Sentencia que aceptó el servidor (SHOW REPLICA/SLAVE STATUS), "" si aún no se sabe
+
+	collectDBSizes  bool
+	dbSizesInterval time.Duration
+	dbSizesExclude  []string
+	dbSizes         map[string]uint64 // Última medición de tamaños por base de datos
+	dbSizesAt       time.Time         // Última consulta de tamaños, aunque fallara
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "mysql",
+		Enabled: func(cfg *config.Config) bool { return cfg.MySQL != nil && cfg.MySQL.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewMySQLCollector(cfg.MySQL)
+		},
+	})
+}
+
+// tlsConfigName es el nombre con el que se registra la configuración TLS en el driver
+const tlsConfigName = "logtick"
+
+// withTLS registra la configuración TLS en el driver y devuelve el DSN que la usa
+func withTLS(dsn string, cfg *config.TLSConfig) (string, error) {
+	tlsConfig, err := cfg.Build("mysql.tls")
+	if err != nil {
+		return "", err
+	}
+	log := logrus.WithField("collector", "mysql")
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		log.WithFields(logrus.Fields{
+			"tls_version":  tls.VersionName(cs.Version),
+			"cipher_suite": tls.CipherSuiteName(cs.CipherSuite),
+		}).Debug("Conexión TLS con MySQL establecida.")
+		return nil
+	}
+
+	parsed, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", fmt.Errorf("DSN de MySQL inválido: %w", err)
+	}
+	if err := mysql.RegisterTLSConfig(tlsConfigName, tlsConfig); err != nil {
+		return "", fmt.Errorf("error al registrar la configuración TLS de MySQL: %w", err)
+	}
+	parsed.TLSConfig = tlsConfigName // El driver toma el ServerName del host del DSN
+	return parsed.FormatDSN(), nil
 }
 
 // NewMySQLCollector crea una nueva instancia de MySQLCollector
@@ -40,35 +134,63 @@ func NewMySQLCollector(cfg *config.MySQLConfig) (*MySQLCollector, error) {
 		return nil, fmt.Errorf("DSN de MySQL no puede estar vacío")
 	}
 
-	db, err := sql.Open("mysql", cfg.DSN)
+	dsn := cfg.DSN
+	if cfg.TLS != nil {
+		var err error
+		if dsn, err = withTLS(dsn, cfg.TLS); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("mysql", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("error al abrir conexión MySQL: %w", err)
 	}
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+	db.SetConnMaxLifetime(connMaxLifetime)
 
 	// Ping para verificar la conexión inicial
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	initTimeout := time.Duration(cfg.InitTimeoutSeconds) * time.Second
+	if initTimeout <= 0 {
+		initTimeout = 5 * time.Second
+	}
+	logrus.WithField("collector", "mysql").WithField("init_timeout", initTimeout.String()).Debug("Verificando conexión inicial con MySQL.")
+
+	ctx, cancel := context.WithTimeout(context.Background(), initTimeout)
 	defer cancel()
 	if err = db.PingContext(ctx); err != nil {
 		db.Close() // Cerrar la conexión si el ping falla
-		return nil, fmt.Errorf("error al conectar con MySQL DSN '%s': %w", cfg.DSN, err)
+		return nil, fmt.Errorf("error al conectar con MySQL DSN '%s' (timeout %s): %w", cfg.DSN, initTimeout, err)
 	}
 
 	return &MySQLCollector{
-		db:       db,
-		dsn:      cfg.DSN,
-		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
-		log:      logrus.WithField("collector", "mysql"),
+		db:           db,
+		dsn:          cfg.DSN,
+		interval:     time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		sampleWindow: time.Duration(cfg.SampleWindowMs) * time.Millisecond,
+		queryTimeout: time.Duration(cfg.QueryTimeoutSeconds) * time.Second,
+		log:          logrus.WithField("collector", "mysql"),
+
+		counterRates:       collector.NewRateTracker(logrus.WithField("collector", "mysql")),
+		collectReplication: cfg.CollectReplication,
+
+		collectDBSizes:  cfg.CollectDBSizes,
+		dbSizesInterval: time.Duration(cfg.DBSizesIntervalSeconds) * time.Second,
+		dbSizesExclude:  cfg.DBSizesExclude,
 	}, nil
 }
 
-// Collect recolecta métricas de MySQL
-func (c *MySQLCollector) Collect() (collector.MetricData, error) {
-	var statusVars map[string]string
-	statusVars = make(map[string]string)
+// readStatus ejecuta SHOW GLOBAL STATUS y devuelve las variables como mapa
+func (c *MySQLCollector) readStatus(ctx context.Context) (map[string]string, error) {
+	statusVars := make(map[string]string)
 
-	rows, err := c.db.Query("SHOW GLOBAL STATUS")
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, "SHOW GLOBAL STATUS")
 	if err != nil {
-		return nil, fmt.Errorf("error al ejecutar 'SHOW GLOBAL STATUS': %w", err)
+		return nil, c.queryError(ctx, "SHOW GLOBAL STATUS", err)
 	}
 	defer rows.Close()
 
@@ -84,11 +206,246 @@ func (c *MySQLCollector) Collect() (collector.MetricData, error) {
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error de fila después de iterar en MySQL status: %w", err)
 	}
+	return statusVars, nil
+}
+
+// queryContext deriva el contexto de una consulta limitado por query_timeout_seconds
+func (c *MySQLCollector) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.queryTimeout)
+}
+
+// queryError envuelve el error de una consulta indicando si se debió al tiempo límite
+func (c *MySQLCollector) queryError(ctx context.Context, query string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("la consulta '%s' a MySQL superó el tiempo límite de %s: %w", query, c.queryTimeout, err)
+	}
+	return fmt.Errorf("error al ejecutar '%s': %w", query, err)
+}
+
+// replicaStatements son las sentencias de estado de réplica en orden de preferencia:
+// SHOW REPLICA STATUS (MySQL 8.0.22+, MariaDB 10.5+) y SHOW SLAVE STATUS para servidores anteriores
+var replicaStatements = []string{"SHOW REPLICA STATUS", "SHOW SLAVE STATUS"}
+
+// readReplicaStatus ejecuta el estado de réplica y devuelve la primera fila por nombre de columna.
+// Devuelve nil sin error si el servidor no es una réplica (sin filas).
+func (c *MySQLCollector) readReplicaStatus(ctx context.Context) (map[string]sql.NullString, error) {
+	statements := replicaStatements
+	if c.replicaStatement != "" {
+		statements = []string{c.replicaStatement}
+	}
+
+	var lastErr error
+	for _, stmt := range statements {
+		row, err := c.queryFirstRow(ctx, stmt)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c.replicaStatement = stmt
+		return row, nil
+	}
+	return nil, lastErr
+}
+
+// queryFirstRow ejecuta una consulta y devuelve su primera fila indexada por nombre de columna
+func (c *MySQLCollector) queryFirstRow(ctx context.Context, query string) (map[string]sql.NullString, error) {
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, c.queryError(ctx, query, err)
+	}
+	defer rows.Close()
 
-	// Funciones auxiliares para convertir a uint64
-	parseUint := func(s string) uint64 {
-		val, _ := strconv.ParseUint(s, 10, 64)
-		return val
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]sql.NullString, len(columns))
+	for i, name := range columns {
+		row[name] = values[i]
+	}
+	return row, nil
+}
+
+// collectReplicationStatus rellena las métricas de replicación a partir del estado de réplica.
+// Las columnas cambiaron de nombre en MySQL 8.0.22 (Source/Replica en lugar de Master/Slave).
+func (c *MySQLCollector) collectReplicationStatus(ctx context.Context, metrics *MySQLMetrics) error {
+	row, err := c.readReplicaStatus(ctx)
+	if err != nil || row == nil {
+		return err // Sin filas: el servidor no es una réplica
+	}
+
+	column := func(names ...string) sql.NullString {
+		for _, name := range names {
+			if v, ok := row[name]; ok {
+				return v
+			}
+		}
+		return sql.NullString{}
+	}
+
+	if lag := column("Seconds_Behind_Source", "Seconds_Behind_Master"); lag.Valid {
+		v := parseUint(lag.String)
+		metrics.SecondsBehindSource = &v
+	}
+	if io := column("Replica_IO_Running", "Slave_IO_Running"); io.Valid {
+		running := io.String == "Yes"
+		metrics.IORunning = &running
+	}
+	if sqlThread := column("Replica_SQL_Running", "Slave_SQL_Running"); sqlThread.Valid {
+		running := sqlThread.String == "Yes"
+		metrics.SQLRunning = &running
+	}
+	return nil
+}
+
+// parseUint convierte un valor de estado a uint64 (0 si no es numérico)
+func parseUint(s string) uint64 {
+	val, _ := strconv.ParseUint(s, 10, 64)
+	return val
+}
+
+// windowRate calcula la tasa por segundo de un contador entre dos muestras
+func windowRate(first, second map[string]string, name string, elapsed time.Duration) *float64 {
+	prev, curr := parseUint(first[name]), parseUint(second[name])
+	rate := 0.0
+	if curr >= prev && elapsed > 0 {
+		rate = float64(curr-prev) / elapsed.Seconds()
+	}
+	return &rate
+}
+
+// rateCounters son las variables de estado cuya tasa se calcula contra la ronda anterior:
+// operaciones de filas de InnoDB y consultas lentas
+var rateCounters = []string{"Innodb_rows_read", "Innodb_rows_inserted", "Innodb_rows_updated", "Innodb_rows_deleted", "Slow_queries"}
+
+// rates calcula la tasa por segundo de cada contador de rateCounters contra la ronda anterior.
+// Devuelve ceros en la primera ronda o si un contador se reinició.
+func (c *MySQLCollector) rates(statusVars map[string]string) map[string]float64 {
+	now := time.Now()
+	rates := make(map[string]float64, len(rateCounters))
+	for _, name := range rateCounters {
+		rates[name] = c.counterRates.Rate(name, parseUint(statusVars[name]), now)
+	}
+	return rates
+}
+
+// readLongQueryTime lee el umbral de consulta lenta (long_query_time, en segundos)
+func (c *MySQLCollector) readLongQueryTime(ctx context.Context) (float64, error) {
+	const query = "SHOW GLOBAL VARIABLES LIKE 'long_query_time'"
+	row, err := c.queryFirstRow(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	value := row["Value"]
+	if !value.Valid {
+		return 0, fmt.Errorf("'%s' no devolvió ningún valor", query)
+	}
+	seconds, err := strconv.ParseFloat(value.String, 64)
+	if err != nil {
+		return 0, fmt.Errorf("long_query_time inválido '%s': %w", value.String, err)
+	}
+	return seconds, nil
+}
+
+// readDatabaseSizes suma datos e índices de las tablas de cada base de datos, omitiendo las de
+// dbSizesExclude
+func (c *MySQLCollector) readDatabaseSizes(ctx context.Context) (map[string]uint64, error) {
+	query := "SELECT table_schema, COALESCE(SUM(data_length + index_length), 0) FROM information_schema.tables"
+	args := make([]interface{}, len(c.dbSizesExclude))
+	if len(c.dbSizesExclude) > 0 {
+		query += " WHERE table_schema NOT IN (?" + strings.Repeat(", ?", len(c.dbSizesExclude)-1) + ")"
+		for i, name := range c.dbSizesExclude {
+			args[i] = name
+		}
+	}
+	query += " GROUP BY table_schema"
+
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, c.queryError(ctx, "information_schema.tables", err)
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]uint64)
+	for rows.Next() {
+		var schema string
+		var size uint64
+		if err := rows.Scan(&schema, &size); err != nil {
+			return nil, fmt.Errorf("error al leer el tamaño de las bases de datos: %w", err)
+		}
+		sizes[schema] = size
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error de fila al leer el tamaño de las bases de datos: %w", err)
+	}
+	return sizes, nil
+}
+
+// databaseSizes devuelve los tamaños por base de datos, repitiendo la consulta solo si pasó
+// db_sizes_interval_seconds desde la anterior. Si falla se registra y se conserva la medición previa.
+func (c *MySQLCollector) databaseSizes(ctx context.Context) map[string]uint64 {
+	if !c.dbSizesAt.IsZero() && time.Since(c.dbSizesAt) < c.dbSizesInterval {
+		return c.dbSizes
+	}
+	c.dbSizesAt = time.Now() // También tras un error, para no repetir una consulta costosa en cada ronda
+	sizes, err := c.readDatabaseSizes(ctx)
+	if err != nil {
+		c.log.WithError(err).Warn("No se pudo obtener el tamaño de las bases de datos de MySQL")
+		return c.dbSizes
+	}
+	c.dbSizes = sizes
+	return sizes
+}
+
+// Collect recolecta métricas de MySQL
+func (c *MySQLCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	// La ronda completa (incluida la ventana de muestreo) no debe solaparse con la siguiente
+	ctx, cancel := context.WithTimeout(ctx, c.interval)
+	defer cancel()
+
+	statusVars, err := c.readStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Segunda muestra tras la ventana corta para calcular tasas instantáneas
+	var firstSample map[string]string
+	var elapsed time.Duration
+	if c.sampleWindow > 0 {
+		firstSample = statusVars
+		start := time.Now()
+		select {
+		case <-time.After(c.sampleWindow):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("ventana de muestreo de MySQL interrumpida: %w", ctx.Err())
+		}
+		statusVars, err = c.readStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		elapsed = time.Since(start)
 	}
 
 	// Calcular InnoDB Buffer Pool Hit Ratio
@@ -108,6 +465,45 @@ func (c *MySQLCollector) Collect() (collector.MetricData, error) {
 		BytesSent:            parseUint(statusVars["Bytes_sent"]),
 		Queries:              parseUint(statusVars["Queries"]),
 		InnodbBufferPoolHits: innodbHitRatio,
+
+		InnodbRowsRead:     parseUint(statusVars["Innodb_rows_read"]),
+		InnodbRowsInserted: parseUint(statusVars["Innodb_rows_inserted"]),
+		InnodbRowsUpdated:  parseUint(statusVars["Innodb_rows_updated"]),
+		InnodbRowsDeleted:  parseUint(statusVars["Innodb_rows_deleted"]),
+
+		SlowQueries:         parseUint(statusVars["Slow_queries"]),
+		TableLocksWaited:    parseUint(statusVars["Table_locks_waited"]),
+		TableLocksImmediate: parseUint(statusVars["Table_locks_immediate"]),
+	}
+
+	rates := c.rates(statusVars)
+	metrics.InnodbRowsReadPerSecond = rates["Innodb_rows_read"]
+	metrics.InnodbRowsInsertedPerSecond = rates["Innodb_rows_inserted"]
+	metrics.InnodbRowsUpdatedPerSecond = rates["Innodb_rows_updated"]
+	metrics.InnodbRowsDeletedPerSecond = rates["Innodb_rows_deleted"]
+	metrics.SlowQueriesPerSecond = rates["Slow_queries"]
+
+	if firstSample != nil {
+		metrics.QueriesPerSecond = windowRate(firstSample, statusVars, "Queries", elapsed)
+		metrics.BytesReceivedPerSecond = windowRate(firstSample, statusVars, "Bytes_received", elapsed)
+		metrics.BytesSentPerSecond = windowRate(firstSample, statusVars, "Bytes_sent", elapsed)
+	}
+
+	// Como el estado de replicación, es opcional: sin permisos se omite sin fallar la ronda
+	if longQueryTime, err := c.readLongQueryTime(ctx); err != nil {
+		c.log.WithError(err).Warn("No se pudo obtener long_query_time de MySQL")
+	} else {
+		metrics.LongQueryTime = &longQueryTime
+	}
+
+	if c.collectDBSizes {
+		metrics.DatabaseSizes = c.databaseSizes(ctx)
+	}
+
+	if c.collectReplication {
+		if err := c.collectReplicationStatus(ctx, metrics); err != nil {
+			c.log.WithError(err).Warn("No se pudo obtener el estado de replicación de MySQL")
+		}
 	}
 
 	c.log.WithFields(logrus.Fields{
@@ -127,3 +523,43 @@ func (c *MySQLCollector) Name() string {
 func (c *MySQLCollector) GetInterval() time.Duration {
 	return c.interval
 }
+
+// Close cierra el pool de conexiones con MySQL.
+// database/sql es seguro para uso concurrente: una recolección en curso recibe un error en lugar de bloquearse.
+func (c *MySQLCollector) Close() error {
+	return c.db.Close()
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *MySQLCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "uptime_seconds", Type: collector.Counter, Unit: collector.UnitSeconds, Description: "Tiempo desde el arranque del servidor."},
+		{Name: "threads_connected", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Conexiones abiertas."},
+		{Name: "threads_running", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Hilos ejecutando consultas."},
+		{Name: "total_connections", Type: collector.Counter, Unit: collector.UnitCount, Description: "Intentos de conexión acumulados."},
+		{Name: "bytes_received", Type: collector.Counter, Unit: collector.UnitBytes},
+		{Name: "bytes_sent", Type: collector.Counter, Unit: collector.UnitBytes},
+		{Name: "queries_total", Type: collector.Counter, Unit: collector.UnitCount, Description: "Consultas ejecutadas."},
+		{Name: "innodb_buffer_pool_reads_hits_ratio", Type: collector.Gauge, Unit: collector.UnitPercent},
+		{Name: "queries_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond, Description: "Medido en la ventana de muestreo."},
+		{Name: "bytes_received_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "bytes_sent_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "innodb_rows_read_total", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "innodb_rows_inserted_total", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "innodb_rows_updated_total", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "innodb_rows_deleted_total", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "innodb_rows_read_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond, Description: "Calculado contra la recolección anterior."},
+		{Name: "innodb_rows_inserted_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "innodb_rows_updated_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "innodb_rows_deleted_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond},
+		{Name: "slow_queries_total", Type: collector.Counter, Unit: collector.UnitCount, Description: "Consultas que superaron long_query_time."},
+		{Name: "slow_queries_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond, Description: "Calculado contra la recolección anterior."},
+		{Name: "table_locks_waited_total", Type: collector.Counter, Unit: collector.UnitCount, Description: "Bloqueos de tabla que tuvieron que esperar."},
+		{Name: "table_locks_immediate_total", Type: collector.Counter, Unit: collector.UnitCount, Description: "Bloqueos de tabla concedidos de inmediato."},
+		{Name: "long_query_time_seconds", Type: collector.Gauge, Unit: collector.UnitSeconds, Description: "Umbral de consulta lenta del servidor."},
+		{Name: "database_size_bytes", Type: collector.Gauge, Unit: collector.UnitBytes, Description: "Datos e índices de cada base de datos."},
+		{Name: "seconds_behind_source", Type: collector.Gauge, Unit: collector.UnitSeconds, Description: "Retraso de la réplica respecto al origen."},
+		{Name: "io_running", Type: collector.Gauge, Unit: collector.UnitNone, Description: "1 si el hilo de E/S de la réplica está en ejecución."},
+		{Name: "sql_running", Type: collector.Gauge, Unit: collector.UnitNone, Description: "1 si el hilo SQL de la réplica está en ejecución."},
+	}
+}
@@ -0,0 +1,208 @@
+package nginx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/config"
+	"github.com/atrox39/logtick/logtail"
+)
+
+// maxWindowSamples limita los tiempos de respuesta guardados por ventana; con más solicitudes se
+// conserva una muestra aleatoria uniforme (reservoir sampling) para que la memoria no crezca con el tráfico
+const maxWindowSamples = 100_000
+
+// logVariable son los nombres de variable de log_format ($status, $upstream_response_time, ...)
+var logVariable = regexp.MustCompile(`\$[a-zA-Z_][a-zA-Z0-9_]*`)
+
+// accessLogParser extrae el estado y el tiempo de respuesta de una línea del access log
+type accessLogParser struct {
+	pattern     *regexp.Regexp
+	statusGroup int
+	timeGroup   int
+}
+
+// newAccessLogParser compila un log_format de Nginx en una expresión regular. Cada variable captura
+// hasta el siguiente carácter literal del formato (ej. hasta la comilla en "$request"), y el texto
+// literal debe coincidir tal cual.
+func newAccessLogParser(format, statusField, timeField string) (*accessLogParser, error) {
+	p := &accessLogParser{}
+	var expr strings.Builder
+	expr.WriteString("^")
+	group := 0
+	last := 0
+	for _, loc := range logVariable.FindAllStringIndex(format, -1) {
+		expr.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+		last = loc[1]
+		group++
+		switch format[loc[0]+1 : loc[1]] {
+		case statusField:
+			p.statusGroup = group
+		case timeField:
+			p.timeGroup = group
+		}
+		if last < len(format) && format[last] != '$' {
+			expr.WriteString("([^" + regexp.QuoteMeta(format[last:last+1]) + "]*)")
+		} else {
+			expr.WriteString("(.*?)")
+		}
+	}
+	expr.WriteString(regexp.QuoteMeta(format[last:]))
+	expr.WriteString("$")
+
+	if p.statusGroup == 0 {
+		return nil, fmt.Errorf("log_format no contiene $%s", statusField)
+	}
+	if p.timeGroup == 0 {
+		return nil, fmt.Errorf("log_format no contiene $%s", timeField)
+	}
+	pattern, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("log_format inválido: %w", err)
+	}
+	p.pattern = pattern
+	return p, nil
+}
+
+// parse devuelve el estado y el tiempo de respuesta en segundos de una línea. ok es false si la
+// línea no sigue el formato; hasTime es false si la solicitud no pasó por un upstream ("-").
+func (p *accessLogParser) parse(line string) (status string, seconds float64, hasTime, ok bool) {
+	m := p.pattern.FindStringSubmatch(line)
+	if m == nil {
+		return "", 0, false, false
+	}
+	seconds, hasTime = parseResponseTime(m[p.timeGroup])
+	return m[p.statusGroup], seconds, hasTime, true
+}
+
+// parseResponseTime suma los tiempos de $upstream_response_time, que lista un valor por upstream
+// intentado separado por comas, o por dos puntos tras una redirección interna ("0.010, 0.020 : 0.005").
+// "-" indica que no hubo upstream.
+func parseResponseTime(value string) (float64, bool) {
+	var total float64
+	found := false
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ':' || r == ' ' }) {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			continue // "-"
+		}
+		total += v
+		found = true
+	}
+	return total, found
+}
+
+// accessLogWindow acumula las solicitudes leídas del access log entre dos recolecciones
+type accessLogWindow struct {
+	requests    uint64
+	parseErrors uint64
+	statuses    map[string]uint64
+	times       []float64 // Muestra de tiempos de respuesta, como mucho maxWindowSamples
+	timed       uint64    // Solicitudes con tiempo de respuesta, incluidas las que no caben en times
+}
+
+// accessLog sigue el access log de Nginx en segundo plano, independiente de stub_status: las
+// solicitudes se acumulan aunque una recolección falle y se reportan en la siguiente.
+type accessLog struct {
+	parser *accessLogParser
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	window accessLogWindow
+}
+
+// newAccessLog empieza a seguir el archivo configurado desde su final
+func newAccessLog(cfg *config.NginxAccessLogConfig, log *logrus.Entry) (*accessLog, error) {
+	parser, err := newAccessLogParser(cfg.LogFormat, cfg.StatusField, cfg.TimeField)
+	if err != nil {
+		return nil, fmt.Errorf("nginx.access_log: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &accessLog{parser: parser, cancel: cancel, done: make(chan struct{})}
+	a.window.statuses = make(map[string]uint64)
+	tailer := logtail.NewTailer(config.LogFileConfig{Path: cfg.Path, Service: "nginx", Level: "info"}, func(_, line, _ string) {
+		a.add(line)
+	})
+	go func() {
+		defer close(a.done)
+		tailer.Run(ctx)
+	}()
+	log.WithField("path", cfg.Path).Info("Siguiendo el access log de Nginx.")
+	return a, nil
+}
+
+// add registra una línea del access log
+func (a *accessLog) add(line string) {
+	status, seconds, hasTime, ok := a.parser.parse(line)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	w := &a.window
+	if !ok {
+		w.parseErrors++
+		return
+	}
+	w.requests++
+	w.statuses[status]++
+	if !hasTime {
+		return
+	}
+	w.timed++
+	if len(w.times) < maxWindowSamples {
+		w.times = append(w.times, seconds)
+	} else if i := rand.Uint64N(w.timed); i < maxWindowSamples {
+		w.times[i] = seconds
+	}
+}
+
+// drain devuelve la ventana acumulada y empieza una nueva
+func (a *accessLog) drain() accessLogWindow {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	w := a.window
+	a.window = accessLogWindow{statuses: make(map[string]uint64)}
+	return w
+}
+
+// apply añade a metrics los datos de la ventana. Los percentiles se omiten si en la ventana no hubo
+// solicitudes con tiempo de respuesta.
+func (w accessLogWindow) apply(metrics *NginxMetrics) {
+	requests, parseErrors := w.requests, w.parseErrors
+	metrics.LogRequests = &requests
+	metrics.LogParseErrors = &parseErrors
+	metrics.ResponsesByStatus = w.statuses
+	if len(w.times) == 0 {
+		return
+	}
+	sort.Float64s(w.times)
+	metrics.ResponseTimeP50 = percentile(w.times, 50)
+	metrics.ResponseTimeP90 = percentile(w.times, 90)
+	metrics.ResponseTimeP99 = percentile(w.times, 99)
+}
+
+// percentile calcula el percentil p de valores ordenados por el método del rango más cercano
+func percentile(sorted []float64, p float64) *float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	v := sorted[rank-1]
+	return &v
+}
+
+// close deja de seguir el archivo
+func (a *accessLog) close() {
+	a.cancel()
+	<-a.done
+}
@@ -12,110 +12,249 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/atrox39/logtick/collector" // Importa el paquete collector para la interfaz
+	"github.com/atrox39/logtick/collector/httpclient"
 	"github.com/atrox39/logtick/config"
 )
 
-// NginxMetrics contiene las métricas específicas de Nginx
+// Formatos de estado soportados
+const (
+	formatStub = "stub" // Texto plano de ngx_http_stub_status_module
+	formatPlus = "plus" // API JSON de Nginx Plus
+)
+
+// NginxMetrics contiene las métricas específicas de Nginx.
+// Con Nginx Plus, Reading y Writing no están disponibles y se reportan a cero; Waiting
+// corresponde a las conexiones inactivas.
 type NginxMetrics struct {
-	ActiveConnections uint64 `json:"active_connections"`
-	Accepts           uint64 `json:"total_accepts"`
-	Handled           uint64 `json:"total_handled"`
-	Requests          uint64 `json:"total_requests"`
-	Reading           uint64 `json:"reading_connections"`
-	Writing           uint64 `json:"writing_connections"`
-	Waiting           uint64 `json:"waiting_connections"`
+	ActiveConnections uint64  `json:"active_connections"`
+	Accepts           uint64  `json:"total_accepts"`
+	Handled           uint64  `json:"total_handled"`
+	Requests          uint64  `json:"total_requests"`
+	RequestsPerSecond float64 `json:"requests_per_second"` // Contra la recolección anterior, cero en la primera
+	Reading           uint64  `json:"reading_connections"`
+	Writing           uint64  `json:"writing_connections"`
+	Waiting           uint64  `json:"waiting_connections"`
+
+	// Solo con format: plus
+	ServerZones map[string]ServerZone `json:"server_zones,omitempty"` // Mapa por nombre de zona
+	Upstreams   map[string]Upstream   `json:"upstreams,omitempty"`    // Mapa por nombre de upstream
+
+	// Solo con access_log: solicitudes leídas del access log desde la recolección anterior
+	LogRequests       *uint64           `json:"access_log_requests,omitempty"`
+	LogParseErrors    *uint64           `json:"access_log_parse_errors,omitempty"` // Líneas que no siguen log_format
+	ResponsesByStatus map[string]uint64 `json:"responses_by_status,omitempty"`     // Por código de estado (ej. "200", "502")
+	ResponseTimeP50   *float64          `json:"response_time_p50_seconds,omitempty"`
+	ResponseTimeP90   *float64          `json:"response_time_p90_seconds,omitempty"`
+	ResponseTimeP99   *float64          `json:"response_time_p99_seconds,omitempty"`
 }
 
 // NginxCollector implementa la interfaz Collector para métricas de Nginx
 type NginxCollector struct {
-	client        *http.Client
-	stubStatusURL string
-	interval      time.Duration
-	log           *logrus.Entry // Logger para este colector
+	client   *http.Client
+	url      string // stub_status_url o api_url según el formato
+	interval time.Duration
+	log      *logrus.Entry // Logger para este colector
+
+	// fetch obtiene las métricas en el formato configurado; se resuelve en el constructor
+	fetch func(ctx context.Context) (*NginxMetrics, error)
+
+	requestRates *collector.RateTracker // Tasa de total_requests
+	accessLog    *accessLog             // nil si no se configuró access_log
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "nginx",
+		Enabled: func(cfg *config.Config) bool { return cfg.Nginx != nil && cfg.Nginx.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewNginxCollector(cfg.Nginx)
+		},
+	})
 }
 
-// NewNginxCollector crea una nueva instancia de NginxCollector
+// NewNginxCollector crea una nueva instancia de NginxCollector.
+// El formato (stub por defecto, o plus) determina la URL y el parser que se usarán.
 func NewNginxCollector(cfg *config.NginxConfig) (*NginxCollector, error) {
-	if cfg.StubStatusURL == "" {
-		return nil, fmt.Errorf("URL de stub_status de Nginx no puede estar vacía")
-	}
-	return &NginxCollector{
-		client:        &http.Client{Timeout: 5 * time.Second},
-		stubStatusURL: cfg.StubStatusURL,
-		interval:      time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
-		log:           logrus.WithField("collector", "nginx"),
-	}, nil
+	c := &NginxCollector{
+		client:   httpclient.New(5 * time.Second),
+		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:      logrus.WithField("collector", "nginx"),
+
+		requestRates: collector.NewRateTracker(logrus.WithField("collector", "nginx")),
+	}
+
+	switch cfg.Format {
+	case "", formatStub:
+		if cfg.StubStatusURL == "" {
+			return nil, fmt.Errorf("URL de stub_status de Nginx no puede estar vacía")
+		}
+		c.url = cfg.StubStatusURL
+		c.fetch = c.fetchStub
+	case formatPlus:
+		if cfg.APIURL == "" {
+			return nil, fmt.Errorf("api_url de Nginx Plus no puede estar vacía")
+		}
+		c.url = strings.TrimRight(cfg.APIURL, "/")
+		c.fetch = c.fetchPlus
+	default:
+		return nil, fmt.Errorf("formato de Nginx no soportado: %s", cfg.Format)
+	}
+
+	if cfg.AccessLog != nil {
+		var err error
+		if c.accessLog, err = newAccessLog(cfg.AccessLog, c.log); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 // Collect recolecta métricas de Nginx
-func (c *NginxCollector) Collect() (collector.MetricData, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.client.Timeout)
+func (c *NginxCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.client.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", c.stubStatusURL, nil)
+	metrics, err := c.fetch(ctx)
+	if err != nil {
+		return nil, err // Las solicitudes del access log se reportan en la siguiente recolección
+	}
+	metrics.RequestsPerSecond = c.requestRates.Rate("requests", metrics.Requests, time.Now())
+	if c.accessLog != nil {
+		c.accessLog.drain().apply(metrics)
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"active_connections": metrics.ActiveConnections,
+		"total_requests":     metrics.Requests,
+	}).Debug("Métricas de Nginx recolectadas")
+
+	return metrics, nil
+}
+
+// get realiza un GET contra Nginx y devuelve la respuesta si el estado es 200
+func (c *NginxCollector) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error al crear solicitud HTTP para Nginx: %w", err)
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error al realizar solicitud HTTP a Nginx '%s': %w", c.stubStatusURL, err)
+		return nil, fmt.Errorf("error al realizar solicitud HTTP a Nginx '%s': %w", url, err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("respuesta inesperada de Nginx: %s", resp.Status)
+		resp.Body.Close()
+		return nil, fmt.Errorf("respuesta inesperada de Nginx en '%s': %s", url, resp.Status)
 	}
+	return resp, nil
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+// fetchStub obtiene y parsea la salida de stub_status
+func (c *NginxCollector) fetchStub(ctx context.Context) (*NginxMetrics, error) {
+	resp, err := c.get(ctx, c.url)
 	if err != nil {
-		return nil, fmt.Errorf("error al leer respuesta de Nginx: %w", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	// Parsear la salida del stub_status de Nginx
-	// Ejemplo de salida:
-	// Active connections: 291
-	// server accepts handled requests
-	//  1156826 1156826 4487778
-	// Reading: 6 Writing: 179 Waiting: 106
-	lines := strings.Split(string(bodyBytes), "\n")
-	if len(lines) < 3 {
-		return nil, fmt.Errorf("salida de stub_status de Nginx inesperada: %s", string(bodyBytes))
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer respuesta de Nginx: %w", err)
 	}
+	return parseStubStatus(string(bodyBytes))
+}
 
+// parseStubStatus parsea la salida de stub_status buscando cada dato por su palabra clave,
+// de modo que tolera líneas en blanco, espacios iniciales y finales de línea CRLF.
+// Ejemplo de salida:
+//
+//	Active connections: 291
+//	server accepts handled requests
+//	 1156826 1156826 4487778
+//	Reading: 6 Writing: 179 Waiting: 106
+//
+// Devuelve un error si falta alguno de los datos en lugar de un resultado incompleto.
+func parseStubStatus(body string) (*NginxMetrics, error) {
 	metrics := &NginxMetrics{}
+	var haveActive, haveCounters bool
+	found := make(map[string]bool, 3) // Reading/Writing/Waiting encontrados
 
-	// Línea 1: Active connections
-	if len(lines[0]) > 0 {
-		activeConnectionsStr := strings.TrimSpace(strings.TrimPrefix(lines[0], "Active connections:"))
-		metrics.ActiveConnections, _ = strconv.ParseUint(activeConnectionsStr, 10, 64)
-	}
+	lines := strings.Split(body, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(line, "Active connections:"):
+			v, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "Active connections:")), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("valor de 'Active connections' inválido en stub_status: %w", err)
+			}
+			metrics.ActiveConnections = v
+			haveActive = true
 
-	// Línea 3: server accepts handled requests
-	if len(lines[2]) > 0 {
-		fields := strings.Fields(lines[2])
-		if len(fields) >= 3 {
-			metrics.Accepts, _ = strconv.ParseUint(fields[0], 10, 64)
-			metrics.Handled, _ = strconv.ParseUint(fields[1], 10, 64)
-			metrics.Requests, _ = strconv.ParseUint(fields[2], 10, 64)
+		case strings.HasPrefix(line, "server accepts handled requests"):
+			// Los contadores están en la siguiente línea no vacía
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == "" {
+				i++
+			}
+			if i+1 >= len(lines) {
+				return nil, fmt.Errorf("faltan los contadores de 'server accepts handled requests' en stub_status")
+			}
+			i++
+			fields := strings.Fields(lines[i])
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("contadores de stub_status incompletos: %q", strings.TrimSpace(lines[i]))
+			}
+			counters := []*uint64{&metrics.Accepts, &metrics.Handled, &metrics.Requests}
+			for k, dest := range counters {
+				v, err := strconv.ParseUint(fields[k], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("contador de stub_status inválido %q: %w", fields[k], err)
+				}
+				*dest = v
+			}
+			haveCounters = true
+
+		case strings.HasPrefix(line, "Reading:"):
+			fields := strings.Fields(line)
+			for k := 0; k+1 < len(fields); k += 2 {
+				var dest *uint64
+				switch fields[k] {
+				case "Reading:":
+					dest = &metrics.Reading
+				case "Writing:":
+					dest = &metrics.Writing
+				case "Waiting:":
+					dest = &metrics.Waiting
+				default:
+					continue
+				}
+				v, err := strconv.ParseUint(fields[k+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("valor de '%s' inválido en stub_status: %w", strings.TrimSuffix(fields[k], ":"), err)
+				}
+				*dest = v
+				found[fields[k]] = true
+			}
 		}
 	}
 
-	// Línea 4: Reading: X Writing: Y Waiting: Z
-	if len(lines[3]) > 0 {
-		parts := strings.Fields(lines[3])
-		if len(parts) >= 6 {
-			metrics.Reading, _ = strconv.ParseUint(parts[1], 10, 64)
-			metrics.Writing, _ = strconv.ParseUint(parts[3], 10, 64)
-			metrics.Waiting, _ = strconv.ParseUint(parts[5], 10, 64)
+	var missing []string
+	if !haveActive {
+		missing = append(missing, "Active connections")
+	}
+	if !haveCounters {
+		missing = append(missing, "server accepts handled requests")
+	}
+	for _, token := range []string{"Reading:", "Writing:", "Waiting:"} {
+		if !found[token] {
+			missing = append(missing, strings.TrimSuffix(token, ":"))
 		}
 	}
-
-	c.log.WithFields(logrus.Fields{
-		"active_connections": metrics.ActiveConnections,
-		"total_requests":     metrics.Requests,
-	}).Debug("Métricas de Nginx recolectadas")
-
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("salida de stub_status de Nginx incompleta, faltan: %s", strings.Join(missing, ", "))
+	}
 	return metrics, nil
 }
 
@@ -128,3 +267,38 @@ func (c *NginxCollector) Name() string {
 func (c *NginxCollector) GetInterval() time.Duration {
 	return c.interval
 }
+
+// Close deja de seguir el access log, si se configuró
+func (c *NginxCollector) Close() error {
+	if c.accessLog != nil {
+		c.accessLog.close()
+	}
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *NginxCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "active_connections", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "total_accepts", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "total_handled", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "total_requests", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "requests_per_second", Type: collector.Gauge, Unit: collector.UnitPerSecond, Description: "Calculado contra la recolección anterior."},
+		{Name: "reading_connections", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "writing_connections", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "waiting_connections", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "processing", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Solicitudes en curso por zona (Nginx Plus)."},
+		{Name: "requests", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "discarded", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "responses", Type: collector.Counter, Unit: collector.UnitCount, Description: "Respuestas por clase de estado."},
+		{Name: "peers_up", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Servidores sanos por upstream (Nginx Plus)."},
+		{Name: "peers_total", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "healthy", Type: collector.Gauge, Unit: collector.UnitNone, Description: "1 si el servidor del upstream está up."},
+		{Name: "access_log_requests", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Solicitudes del access log desde la recolección anterior."},
+		{Name: "access_log_parse_errors", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "responses_by_status", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Solicitudes por código de estado desde la recolección anterior."},
+		{Name: "response_time_p50_seconds", Type: collector.Gauge, Unit: collector.UnitSeconds, Description: "Percentil 50 del tiempo de respuesta en la ventana."},
+		{Name: "response_time_p90_seconds", Type: collector.Gauge, Unit: collector.UnitSeconds},
+		{Name: "response_time_p99_seconds", Type: collector.Gauge, Unit: collector.UnitSeconds},
+	}
+}
@@ -0,0 +1,90 @@
+package nginx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStubStatus(t *testing.T) {
+	want := NginxMetrics{
+		ActiveConnections: 291,
+		Accepts:           1156826,
+		Handled:           1156825,
+		Requests:          4487778,
+		Reading:           6,
+		Writing:           179,
+		Waiting:           106,
+	}
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "salida estándar",
+			body: "Active connections: 291 \nserver accepts handled requests\n 1156826 1156825 4487778 \nReading: 6 Writing: 179 Waiting: 106 \n",
+		},
+		{
+			name: "espacios iniciales",
+			body: "   Active connections: 291\n  server accepts handled requests\n\t1156826 1156825 4487778\n  Reading: 6 Writing: 179 Waiting: 106\n",
+		},
+		{
+			name: "finales de línea CRLF",
+			body: "Active connections: 291\r\nserver accepts handled requests\r\n 1156826 1156825 4487778\r\nReading: 6 Writing: 179 Waiting: 106\r\n",
+		},
+		{
+			name: "líneas en blanco antes de los contadores",
+			body: "Active connections: 291\nserver accepts handled requests\n\n 1156826 1156825 4487778\nReading: 6 Writing: 179 Waiting: 106\n",
+		},
+		{
+			name:    "sin la línea de contadores",
+			body:    "Active connections: 291\nserver accepts handled requests\n",
+			wantErr: true,
+		},
+		{
+			name:    "contadores incompletos",
+			body:    "Active connections: 291\nserver accepts handled requests\n 1156826 1156825\nReading: 6 Writing: 179 Waiting: 106\n",
+			wantErr: true,
+		},
+		{
+			name:    "sin la línea Reading",
+			body:    "Active connections: 291\nserver accepts handled requests\n 1156826 1156825 4487778\n",
+			wantErr: true,
+		},
+		{
+			name:    "sin Active connections",
+			body:    "server accepts handled requests\n 1156826 1156825 4487778\nReading: 6 Writing: 179 Waiting: 106\n",
+			wantErr: true,
+		},
+		{
+			name:    "cuerpo truncado",
+			body:    "Active connections: 291\nserver acc",
+			wantErr: true,
+		},
+		{
+			name:    "cuerpo vacío",
+			body:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStubStatus(tt.body)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("se esperaba un error, se obtuvo %+v", got)
+				}
+				if got != nil {
+					t.Errorf("con error se esperaba un resultado nil, se obtuvo %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("parseStubStatus = %+v, se esperaba %+v", *got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package nginx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ServerZone contiene el tráfico de una zona de servidor de Nginx Plus
+type ServerZone struct {
+	Processing uint64            `json:"processing"` // Solicitudes en curso
+	Requests   uint64            `json:"requests"`   // Acumulado desde el arranque
+	Discarded  uint64            `json:"discarded"`  // Solicitudes completadas sin enviar respuesta
+	Responses  map[string]uint64 `json:"responses"`  // Respuestas acumuladas por clase: 1xx, 2xx, 3xx, 4xx, 5xx
+}
+
+// UpstreamPeer contiene el estado de un servidor de un upstream
+type UpstreamPeer struct {
+	State    string `json:"state"`   // up, down, unavail, unhealthy, checking o draining
+	Healthy  bool   `json:"healthy"` // true solo si el estado es up
+	Active   uint64 `json:"active"`  // Conexiones activas
+	Requests uint64 `json:"requests"`
+	Fails    uint64 `json:"fails"`
+}
+
+// Upstream contiene la salud de un grupo de upstream
+type Upstream struct {
+	PeersUp    int                     `json:"peers_up"`
+	PeersTotal int                     `json:"peers_total"`
+	Peers      map[string]UpstreamPeer `json:"peers"` // Mapa por dirección del servidor
+}
+
+// plusConnections es la respuesta de GET /connections
+type plusConnections struct {
+	Accepted uint64 `json:"accepted"`
+	Dropped  uint64 `json:"dropped"`
+	Active   uint64 `json:"active"`
+	Idle     uint64 `json:"idle"`
+}
+
+// plusRequests es la respuesta de GET /http/requests
+type plusRequests struct {
+	Total uint64 `json:"total"`
+}
+
+// plusServerZone es cada entrada de GET /http/server_zones
+type plusServerZone struct {
+	Processing uint64 `json:"processing"`
+	Requests   uint64 `json:"requests"`
+	Discarded  uint64 `json:"discarded"`
+	Responses  struct {
+		Class1xx uint64 `json:"1xx"`
+		Class2xx uint64 `json:"2xx"`
+		Class3xx uint64 `json:"3xx"`
+		Class4xx uint64 `json:"4xx"`
+		Class5xx uint64 `json:"5xx"`
+	} `json:"responses"`
+}
+
+// plusUpstream es cada entrada de GET /http/upstreams
+type plusUpstream struct {
+	Peers []struct {
+		Server   string `json:"server"`
+		State    string `json:"state"`
+		Active   uint64 `json:"active"`
+		Requests uint64 `json:"requests"`
+		Fails    uint64 `json:"fails"`
+	} `json:"peers"`
+}
+
+// getJSON realiza un GET contra la API de Nginx Plus y decodifica la respuesta
+func (c *NginxCollector) getJSON(ctx context.Context, path string, out interface{}) error {
+	resp, err := c.get(ctx, c.url+path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error al decodificar respuesta de Nginx Plus en '%s': %w", path, err)
+	}
+	return nil
+}
+
+// fetchPlus obtiene las métricas de la API JSON de Nginx Plus (api_url incluye la versión, ej. /api/9).
+// Las conexiones y solicitudes son obligatorias; las zonas y upstreams son complementarias.
+func (c *NginxCollector) fetchPlus(ctx context.Context) (*NginxMetrics, error) {
+	var conns plusConnections
+	if err := c.getJSON(ctx, "/connections", &conns); err != nil {
+		return nil, err
+	}
+	var requests plusRequests
+	if err := c.getJSON(ctx, "/http/requests", &requests); err != nil {
+		return nil, err
+	}
+
+	metrics := &NginxMetrics{
+		ActiveConnections: conns.Active,
+		Accepts:           conns.Accepted,
+		Handled:           conns.Accepted - conns.Dropped,
+		Requests:          requests.Total,
+		Waiting:           conns.Idle,
+		ServerZones:       make(map[string]ServerZone),
+		Upstreams:         make(map[string]Upstream),
+	}
+
+	var zones map[string]plusServerZone
+	if err := c.getJSON(ctx, "/http/server_zones", &zones); err != nil {
+		c.log.WithError(err).Warn("No se pudieron obtener las zonas de servidor de Nginx Plus")
+	}
+	for name, z := range zones {
+		metrics.ServerZones[name] = ServerZone{
+			Processing: z.Processing,
+			Requests:   z.Requests,
+			Discarded:  z.Discarded,
+			Responses: map[string]uint64{
+				"1xx": z.Responses.Class1xx,
+				"2xx": z.Responses.Class2xx,
+				"3xx": z.Responses.Class3xx,
+				"4xx": z.Responses.Class4xx,
+				"5xx": z.Responses.Class5xx,
+			},
+		}
+	}
+
+	var upstreams map[string]plusUpstream
+	if err := c.getJSON(ctx, "/http/upstreams", &upstreams); err != nil {
+		c.log.WithError(err).Warn("No se pudieron obtener los upstreams de Nginx Plus")
+	}
+	for name, u := range upstreams {
+		upstream := Upstream{PeersTotal: len(u.Peers), Peers: make(map[string]UpstreamPeer, len(u.Peers))}
+		for _, p := range u.Peers {
+			healthy := p.State == "up"
+			if healthy {
+				upstream.PeersUp++
+			}
+			upstream.Peers[p.Server] = UpstreamPeer{
+				State:    p.State,
+				Healthy:  healthy,
+				Active:   p.Active,
+				Requests: p.Requests,
+				Fails:    p.Fails,
+			}
+		}
+		metrics.Upstreams[name] = upstream
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,186 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"syscall"
+	"time"
+
+	gnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// Listener describe un socket a la escucha
+type Listener struct {
+	Protocol string `json:"protocol"` // tcp, tcp6, udp o udp6
+	Address  string `json:"address"`  // Dirección local (0.0.0.0 / :: = todas las interfaces)
+	Port     uint32 `json:"port"`
+	PID      int32  `json:"pid,omitempty"`     // 0 si no se pudo asociar a un proceso (permisos)
+	Process  string `json:"process,omitempty"` // Nombre del proceso propietario
+}
+
+// PortsMetrics contiene el inventario de puertos a la escucha del host
+type PortsMetrics struct {
+	Listeners    []Listener `json:"listeners"`
+	Count        int        `json:"count"`
+	Unattributed int        `json:"unattributed"` // Sockets sin proceso asociado (normalmente por falta de permisos)
+}
+
+// PortsCollector implementa la interfaz Collector para el inventario de puertos a la escucha
+type PortsCollector struct {
+	includeLoopback bool
+	interval        time.Duration
+	log             *logrus.Entry
+
+	warnedUnattributed bool // Advertir una sola vez de la falta de permisos
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "ports",
+		Enabled: func(cfg *config.Config) bool { return cfg.Ports != nil && cfg.Ports.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewPortsCollector(cfg.Ports)
+		},
+	})
+}
+
+// NewPortsCollector crea una nueva instancia de PortsCollector.
+// Falla si el sistema no permite enumerar los sockets.
+func NewPortsCollector(cfg *config.PortsConfig) (*PortsCollector, error) {
+	if _, err := gnet.Connections("inet"); err != nil {
+		return nil, fmt.Errorf("no se pudieron enumerar los sockets del sistema: %w", err)
+	}
+	return &PortsCollector{
+		includeLoopback: cfg.IncludeLoopback,
+		interval:        time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:             logrus.WithField("collector", "ports"),
+	}, nil
+}
+
+// protocolName devuelve el nombre del protocolo de un socket, o "" si no es TCP/UDP
+func protocolName(conn gnet.ConnectionStat) string {
+	var proto string
+	switch conn.Type {
+	case syscall.SOCK_STREAM:
+		proto = "tcp"
+	case syscall.SOCK_DGRAM:
+		proto = "udp"
+	default:
+		return ""
+	}
+	if conn.Family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	return proto
+}
+
+// isListening indica si un socket está a la escucha: TCP en estado LISTEN o UDP sin destino
+func isListening(proto string, conn gnet.ConnectionStat) bool {
+	if proto == "tcp" || proto == "tcp6" {
+		return conn.Status == "LISTEN"
+	}
+	return conn.Raddr.Port == 0
+}
+
+// Collect enumera los sockets TCP/UDP a la escucha y los asocia a su proceso.
+// Sin privilegios, los sockets de otros usuarios se reportan sin PID ni proceso.
+func (c *PortsCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	conns, err := gnet.ConnectionsWithContext(ctx, "inet")
+	if err != nil {
+		return nil, fmt.Errorf("error al enumerar los sockets: %w", err)
+	}
+
+	metrics := &PortsMetrics{Listeners: []Listener{}}
+	names := make(map[int32]string) // Caché de nombres de proceso por PID en esta ronda
+	seen := make(map[Listener]bool) // Un socket compartido por varios procesos aparece repetido
+
+	for _, conn := range conns {
+		proto := protocolName(conn)
+		if proto == "" || !isListening(proto, conn) {
+			continue
+		}
+		if ip := net.ParseIP(conn.Laddr.IP); !c.includeLoopback && ip != nil && ip.IsLoopback() {
+			continue
+		}
+
+		l := Listener{Protocol: proto, Address: conn.Laddr.IP, Port: conn.Laddr.Port, PID: conn.Pid}
+		if l.PID > 0 {
+			name, ok := names[l.PID]
+			if !ok {
+				if p, err := process.NewProcess(l.PID); err == nil {
+					name, _ = p.Name()
+				}
+				names[l.PID] = name
+			}
+			l.Process = name
+		}
+		if seen[l] {
+			continue
+		}
+		seen[l] = true
+
+		if l.PID == 0 {
+			metrics.Unattributed++
+		}
+		metrics.Listeners = append(metrics.Listeners, l)
+	}
+
+	sort.Slice(metrics.Listeners, func(i, j int) bool {
+		a, b := metrics.Listeners[i], metrics.Listeners[j]
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.PID < b.PID
+	})
+	metrics.Count = len(metrics.Listeners)
+
+	if metrics.Unattributed > 0 && !c.warnedUnattributed {
+		c.log.WithField("unattributed", metrics.Unattributed).Warn("Algunos puertos no se pudieron asociar a su proceso (¿el agente no se ejecuta como root?)")
+		c.warnedUnattributed = true
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"listeners":    metrics.Count,
+		"unattributed": metrics.Unattributed,
+	}).Debug("Inventario de puertos a la escucha recolectado")
+
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *PortsCollector) Name() string {
+	return "ports"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *PortsCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *PortsCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *PortsCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "count", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Sockets TCP/UDP a la escucha."},
+		{Name: "unattributed", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Sockets sin proceso asociado por falta de permisos."},
+		{Name: "port", Type: collector.Gauge, Unit: collector.UnitNone},
+	}
+}
@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq" // Driver de PostgreSQL
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// Límites del pool de conexiones: el colector ejecuta sus consultas en secuencia, así que
+// pocas conexiones bastan, y renovarlas periódicamente evita usar conexiones cerradas por el servidor
+const (
+	maxOpenConns    = 2
+	connMaxLifetime = 5 * time.Minute
+)
+
+// DatabaseStats contiene la actividad acumulada de una base de datos según pg_stat_database
+type DatabaseStats struct {
+	Backends      int64   `json:"backends"`        // Conexiones abiertas a la base de datos
+	XactCommit    int64   `json:"xact_commit"`     // Transacciones confirmadas desde el último reinicio de estadísticas
+	XactRollback  int64   `json:"xact_rollback"`   // Transacciones revertidas
+	Deadlocks     int64   `json:"deadlocks"`       // Interbloqueos detectados
+	CacheHitRatio float64 `json:"cache_hit_ratio"` // Porcentaje de bloques leídos desde shared_buffers
+}
+
+// PostgresMetrics contiene las métricas específicas de PostgreSQL
+type PostgresMetrics struct {
+	ActiveConnections int64                    `json:"active_connections"` // Conexiones de cliente ejecutando una consulta
+	TotalConnections  int64                    `json:"total_connections"`  // Conexiones de cliente en cualquier estado
+	ConnectionsStates map[string]int64         `json:"connections_by_state"`
+	XactCommit        int64                    `json:"xact_commit"` // Suma de todas las bases de datos
+	XactRollback      int64                    `json:"xact_rollback"`
+	Deadlocks         int64                    `json:"deadlocks"`
+	CacheHitRatio     float64                  `json:"cache_hit_ratio"`
+	Databases         map[string]DatabaseStats `json:"databases"` // Mapa por nombre de base de datos
+}
+
+// PostgresCollector implementa la interfaz Collector para métricas de PostgreSQL
+type PostgresCollector struct {
+	db           *sql.DB
+	interval     time.Duration
+	queryTimeout time.Duration // Tiempo máximo de cada consulta
+	log          *logrus.Entry
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "postgres",
+		Enabled: func(cfg *config.Config) bool { return cfg.Postgres != nil && cfg.Postgres.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewPostgresCollector(cfg.Postgres)
+		},
+	})
+}
+
+// NewPostgresCollector crea una nueva instancia de PostgresCollector y verifica la conexión
+func NewPostgresCollector(cfg *config.PostgresConfig) (*PostgresCollector, error) {
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("DSN de PostgreSQL no puede estar vacío")
+	}
+
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir conexión PostgreSQL: %w", err)
+	}
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+	db.SetConnMaxLifetime(connMaxLifetime)
+
+	c := &PostgresCollector{
+		db:           db,
+		interval:     time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		queryTimeout: time.Duration(cfg.QueryTimeoutSeconds) * time.Second,
+		log:          logrus.WithField("collector", "postgres"),
+	}
+
+	ctx, cancel := c.queryContext(context.Background())
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close() // Cerrar el pool si el ping falla
+		return nil, fmt.Errorf("error al conectar con PostgreSQL: %w", err)
+	}
+	return c, nil
+}
+
+// queryContext deriva un contexto acotado por el tiempo límite de consulta
+func (c *PostgresCollector) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.queryTimeout)
+}
+
+// queryError envuelve el error de una consulta indicando si se debió al tiempo límite
+func (c *PostgresCollector) queryError(ctx context.Context, query string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("la consulta a %s en PostgreSQL superó el tiempo límite de %s: %w", query, c.queryTimeout, err)
+	}
+	return fmt.Errorf("error al consultar %s: %w", query, err)
+}
+
+// cacheHitRatio devuelve el porcentaje de bloques servidos desde la caché (cero sin lecturas)
+func cacheHitRatio(hit, read int64) float64 {
+	if hit+read == 0 {
+		return 0
+	}
+	return float64(hit) / float64(hit+read) * 100
+}
+
+// databaseStatsQuery lee la actividad de cada base de datos; se omiten las plantillas y las
+// filas de objetos compartidos (datname NULL en PostgreSQL 12+)
+const databaseStatsQuery = `SELECT datname, numbackends, xact_commit, xact_rollback, deadlocks, blks_hit, blks_read
+FROM pg_stat_database d
+WHERE datname IS NOT NULL AND NOT EXISTS (SELECT 1 FROM pg_database t WHERE t.datname = d.datname AND t.datistemplate)`
+
+// collectDatabases rellena las estadísticas por base de datos y sus totales
+func (c *PostgresCollector) collectDatabases(ctx context.Context, metrics *PostgresMetrics) error {
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, databaseStatsQuery)
+	if err != nil {
+		return c.queryError(ctx, "pg_stat_database", err)
+	}
+	defer rows.Close()
+
+	var totalHit, totalRead int64
+	for rows.Next() {
+		var name string
+		var stats DatabaseStats
+		var hit, read int64
+		if err := rows.Scan(&name, &stats.Backends, &stats.XactCommit, &stats.XactRollback, &stats.Deadlocks, &hit, &read); err != nil {
+			return fmt.Errorf("error al leer fila de pg_stat_database: %w", err)
+		}
+		stats.CacheHitRatio = cacheHitRatio(hit, read)
+		metrics.Databases[name] = stats
+
+		metrics.XactCommit += stats.XactCommit
+		metrics.XactRollback += stats.XactRollback
+		metrics.Deadlocks += stats.Deadlocks
+		totalHit += hit
+		totalRead += read
+	}
+	if err := rows.Err(); err != nil {
+		return c.queryError(ctx, "pg_stat_database", err)
+	}
+	metrics.CacheHitRatio = cacheHitRatio(totalHit, totalRead)
+	return nil
+}
+
+// activityQuery cuenta las conexiones de cliente por estado (idle, active, idle in transaction, ...)
+const activityQuery = `SELECT COALESCE(state, 'unknown'), count(*)
+FROM pg_stat_activity
+WHERE backend_type = 'client backend'
+GROUP BY 1`
+
+// collectActivity rellena el número de conexiones de cliente en cada estado
+func (c *PostgresCollector) collectActivity(ctx context.Context, metrics *PostgresMetrics) error {
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, activityQuery)
+	if err != nil {
+		return c.queryError(ctx, "pg_stat_activity", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var state string
+		var count int64
+		if err := rows.Scan(&state, &count); err != nil {
+			return fmt.Errorf("error al leer fila de pg_stat_activity: %w", err)
+		}
+		metrics.ConnectionsStates[state] = count
+		metrics.TotalConnections += count
+		if state == "active" {
+			metrics.ActiveConnections = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return c.queryError(ctx, "pg_stat_activity", err)
+	}
+	return nil
+}
+
+// Collect consulta pg_stat_database y pg_stat_activity
+func (c *PostgresCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	metrics := &PostgresMetrics{
+		ConnectionsStates: make(map[string]int64),
+		Databases:         make(map[string]DatabaseStats),
+	}
+
+	if err := c.collectDatabases(ctx, metrics); err != nil {
+		return nil, err
+	}
+	if err := c.collectActivity(ctx, metrics); err != nil {
+		return nil, err
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"databases":          len(metrics.Databases),
+		"active_connections": metrics.ActiveConnections,
+	}).Debug("Métricas de PostgreSQL recolectadas")
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *PostgresCollector) Name() string {
+	return "postgres"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *PostgresCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close cierra el pool de conexiones con PostgreSQL
+func (c *PostgresCollector) Close() error {
+	return c.db.Close()
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *PostgresCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "active_connections", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Conexiones de cliente ejecutando una consulta."},
+		{Name: "total_connections", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Conexiones de cliente en cualquier estado."},
+		{Name: "connections_by_state", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Conexiones de cliente por estado."},
+		{Name: "xact_commit", Type: collector.Counter, Unit: collector.UnitCount, Description: "Transacciones confirmadas."},
+		{Name: "xact_rollback", Type: collector.Counter, Unit: collector.UnitCount, Description: "Transacciones revertidas."},
+		{Name: "deadlocks", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "cache_hit_ratio", Type: collector.Gauge, Unit: collector.UnitPercent, Description: "Bloques leídos desde shared_buffers."},
+		{Name: "backends", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Conexiones abiertas por base de datos."},
+	}
+}
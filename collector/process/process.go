@@ -1,10 +1,17 @@
 package process
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/process"
 	"github.com/sirupsen/logrus"
 
@@ -21,6 +28,7 @@ type ProcessInfo struct {
 	MemoryRSS     uint64  `json:"memory_rss_bytes"` // Resident Set Size
 	NumThreads    int32   `json:"num_threads"`
 	Status        string  `json:"status"`
+	ProcessCount  int     `json:"process_count,omitempty"` // Solo con aggregate: PIDs sumados en esta entrada
 }
 
 // ProcessMetrics contiene las métricas específicas de los procesos monitoreados
@@ -30,56 +38,176 @@ type ProcessMetrics struct {
 
 // ProcessCollector implementa la interfaz Collector para métricas de procesos
 type ProcessCollector struct {
-	processNames []string
-	interval     time.Duration
-	log          *logrus.Entry
+	matchMode      string   // "exact", "prefix", "contains" o "regex"
+	targets        []target // Nombres configurados en línea
+	namesFile      string   // Archivo opcional con más nombres, releído cuando cambia
+	fileTargets    []target // Últimos nombres leídos de namesFile
+	fileModTime    time.Time
+	interval       time.Duration
+	topN           int                 // Máximo de PIDs por nombre (0 = sin límite)
+	topBy          string              // "cpu" o "memory"
+	floatPrecision int                 // Decimales de los porcentajes (-1 = sin redondeo)
+	aggregate      bool                // Reportar una sola entrada por nombre con la suma de sus PIDs
+	cpuSamples     map[int32]cpuSample // Última muestra de CPU por PID, para calcular el uso entre rondas
+	source         processSource       // Lista los procesos del sistema
+	log            *logrus.Entry
+}
+
+// processHandle son los datos que el colector lee de un proceso. *process.Process de gopsutil los
+// ofrece a través de gopsutilProcess; los tests usan procesos falsos.
+type processHandle interface {
+	PID() int32
+	NameWithContext(ctx context.Context) (string, error)
+	TimesWithContext(ctx context.Context) (*cpu.TimesStat, error)
+	CreateTimeWithContext(ctx context.Context) (int64, error)
+	MemoryPercentWithContext(ctx context.Context) (float32, error)
+	MemoryInfoWithContext(ctx context.Context) (*process.MemoryInfoStat, error)
+	NumThreadsWithContext(ctx context.Context) (int32, error)
+	StatusWithContext(ctx context.Context) ([]string, error)
+}
+
+// processSource devuelve los procesos en ejecución
+type processSource func(ctx context.Context) ([]processHandle, error)
+
+// gopsutilProcess adapta *process.Process a processHandle
+type gopsutilProcess struct {
+	*process.Process
+}
+
+// PID devuelve el identificador del proceso
+func (p gopsutilProcess) PID() int32 {
+	return p.Pid
+}
+
+// listProcesses es la fuente de procesos real, basada en gopsutil
+func listProcesses(ctx context.Context) ([]processHandle, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	handles := make([]processHandle, len(procs))
+	for i, p := range procs {
+		handles[i] = gopsutilProcess{p}
+	}
+	return handles, nil
+}
+
+// cpuSample es el tiempo de CPU acumulado de un proceso en un instante dado
+type cpuSample struct {
+	createTime int64     // Detecta la reutilización del PID por otro proceso
+	total      float64   // Segundos de CPU (usuario + sistema) consumidos desde el arranque del proceso
+	at         time.Time // Momento de la lectura
+}
+
+// target es un nombre configurado junto con la función que decide si un proceso coincide con él
+type target struct {
+	name  string
+	match func(processName string) bool
+}
+
+// newTarget prepara la comparación de name según mode. Salvo en modo regex, la comparación no
+// distingue mayúsculas; en regex puede usarse (?i) en el propio patrón.
+func newTarget(name, mode string) (target, error) {
+	lower := strings.ToLower(name)
+	t := target{name: name}
+	switch mode {
+	case "prefix":
+		t.match = func(p string) bool { return strings.HasPrefix(strings.ToLower(p), lower) }
+	case "contains":
+		t.match = func(p string) bool { return strings.Contains(strings.ToLower(p), lower) }
+	case "regex":
+		re, err := regexp.Compile(name)
+		if err != nil {
+			return target{}, fmt.Errorf("expresión regular inválida '%s': %w", name, err)
+		}
+		t.match = re.MatchString
+	default:
+		t.match = func(p string) bool { return strings.EqualFold(p, name) }
+	}
+	return t, nil
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "process",
+		Enabled: func(cfg *config.Config) bool { return cfg.Process != nil && cfg.Process.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewProcessCollector(cfg.Process)
+		},
+	})
 }
 
 // NewProcessCollector crea una nueva instancia de ProcessCollector
 func NewProcessCollector(cfg *config.ProcessConfig) (*ProcessCollector, error) {
-	if len(cfg.ProcessNames) == 0 {
-		return nil, fmt.Errorf("se requiere al menos un nombre de proceso para monitorear")
+	if len(cfg.ProcessNames) == 0 && cfg.ProcessNamesFile == "" {
+		return nil, fmt.Errorf("se requiere al menos un nombre de proceso o un archivo de nombres para monitorear")
+	}
+
+	targets := make([]target, 0, len(cfg.ProcessNames))
+	for _, name := range cfg.ProcessNames {
+		t, err := newTarget(name, cfg.MatchMode)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+
+	floatPrecision := -1
+	if cfg.FloatPrecision != nil {
+		floatPrecision = *cfg.FloatPrecision
 	}
 
 	return &ProcessCollector{
-		processNames: cfg.ProcessNames,
-		interval:     time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
-		log:          logrus.WithField("collector", "process"),
+		matchMode:      cfg.MatchMode,
+		targets:        targets,
+		namesFile:      cfg.ProcessNamesFile,
+		interval:       time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		topN:           cfg.TopN,
+		topBy:          cfg.TopBy,
+		floatPrecision: floatPrecision,
+		aggregate:      cfg.Aggregate,
+		cpuSamples:     make(map[int32]cpuSample),
+		source:         listProcesses,
+		log:            logrus.WithField("collector", "process"),
 	}, nil
 }
 
 // Collect recolecta métricas de procesos
-func (c *ProcessCollector) Collect() (collector.MetricData, error) {
-	allProcs, err := process.Processes()
+func (c *ProcessCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	allProcs, err := c.source(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener la lista de procesos: %w", err)
 	}
 
 	monitored := make(map[string][]ProcessInfo)
+	aggregated := make(map[string]*ProcessInfo)
+	targets := c.currentTargets()
+	now := time.Now()
+	seen := make(map[int32]bool)
 
 	for _, p := range allProcs {
-		pName, err := p.Name()
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("recolección de procesos interrumpida: %w", err)
+		}
+		pName, err := p.NameWithContext(ctx)
 		if err != nil {
 			// Podría ser un proceso zombie o sin permisos, lo ignoramos
 			continue
 		}
 
-		// Normalizar el nombre del proceso para comparar (ej. "mysqld" vs "mysqld_safe")
-		normalizedPName := strings.ToLower(pName)
-
-		for _, targetName := range c.processNames {
-			normalizedTargetName := strings.ToLower(targetName)
-
-			if strings.Contains(normalizedPName, normalizedTargetName) { // Usamos Contains para mayor flexibilidad
+		for _, t := range targets {
+			if t.match(pName) {
 				// Recolectar métricas del proceso
-				cpuPercent, _ := p.CPUPercent() // Requiere llamar dos veces para obtener delta real, 0ms en primera llamada
-				memPercent, _ := p.MemoryPercent()
-				memInfo, _ := p.MemoryInfo()
-				numThreads, _ := p.NumThreads()
-				status, _ := p.Status()
+				cpuPercent := c.cpuPercent(ctx, p, now)
+				seen[p.PID()] = true
+				memPercent, _ := p.MemoryPercentWithContext(ctx)
+				memInfo, _ := p.MemoryInfoWithContext(ctx)
+				numThreads, _ := p.NumThreadsWithContext(ctx)
+				status, _ := p.StatusWithContext(ctx)
 
 				info := ProcessInfo{
-					PID:           p.Pid,
+					PID:           p.PID(),
 					Name:          pName,
 					CPUPercent:    cpuPercent,
 					MemoryPercent: memPercent,
@@ -87,12 +215,29 @@ func (c *ProcessCollector) Collect() (collector.MetricData, error) {
 					NumThreads:    numThreads,
 					Status:        strings.Join(status, ","), // Status puede ser un slice de strings
 				}
-				monitored[targetName] = append(monitored[targetName], info)
+				if c.aggregate {
+					addToAggregate(aggregated, t.name, info)
+				} else {
+					monitored[t.name] = append(monitored[t.name], info)
+				}
 				break // Ya encontramos una coincidencia para este proceso, pasar al siguiente PID
 			}
 		}
 	}
 
+	for name, total := range aggregated {
+		monitored[name] = []ProcessInfo{*total}
+	}
+
+	// Olvidar los PIDs que ya no existen o dejaron de coincidir
+	for pid := range c.cpuSamples {
+		if !seen[pid] {
+			delete(c.cpuSamples, pid)
+		}
+	}
+
+	c.compact(monitored)
+
 	metrics := &ProcessMetrics{
 		MonitoredProcesses: monitored,
 	}
@@ -106,6 +251,116 @@ func (c *ProcessCollector) Collect() (collector.MetricData, error) {
 	return metrics, nil
 }
 
+// addToAggregate suma info a la entrada acumulada del nombre configurado. La entrada acumulada no
+// tiene PID ni estado y se nombra como el objetivo.
+func addToAggregate(aggregated map[string]*ProcessInfo, name string, info ProcessInfo) {
+	total, ok := aggregated[name]
+	if !ok {
+		total = &ProcessInfo{Name: name}
+		aggregated[name] = total
+	}
+	total.CPUPercent += info.CPUPercent
+	total.MemoryPercent += info.MemoryPercent
+	total.MemoryRSS += info.MemoryRSS
+	total.NumThreads += info.NumThreads
+	total.ProcessCount++
+}
+
+// cpuPercent calcula el uso de CPU del proceso desde la ronda anterior a partir de su tiempo de
+// CPU acumulado. p.CPUPercent() de gopsutil promedia desde el arranque del proceso y necesita dos
+// llamadas sobre el mismo objeto, que se recrea en cada ronda. La primera vez que se ve un PID
+// devuelve 0 y solo guarda la muestra. Como en top, 100 equivale a un núcleo completo.
+func (c *ProcessCollector) cpuPercent(ctx context.Context, p processHandle, now time.Time) float64 {
+	times, err := p.TimesWithContext(ctx)
+	if err != nil {
+		return 0
+	}
+	createTime, _ := p.CreateTimeWithContext(ctx)
+	current := cpuSample{createTime: createTime, total: times.User + times.System, at: now}
+
+	prev, ok := c.cpuSamples[p.PID()]
+	c.cpuSamples[p.PID()] = current
+	if !ok || prev.createTime != createTime {
+		return 0
+	}
+
+	elapsed := now.Sub(prev.at).Seconds()
+	delta := current.total - prev.total
+	if elapsed <= 0 || delta < 0 {
+		return 0
+	}
+	return delta / elapsed * 100
+}
+
+// currentTargets combina los nombres en línea con los del archivo, releyéndolo si cambió.
+// Un archivo ausente, vacío o ilegible no detiene la recolección: se usan los nombres en línea
+// (y los últimos leídos del archivo si ya no se puede leer). Los patrones inválidos del archivo
+// se ignoran con un aviso.
+func (c *ProcessCollector) currentTargets() []target {
+	if c.namesFile == "" {
+		return c.targets
+	}
+
+	info, err := os.Stat(c.namesFile)
+	if err != nil {
+		if c.fileTargets == nil {
+			c.log.WithError(err).Warn("No se pudo acceder al archivo de nombres de procesos")
+		}
+	} else if !info.ModTime().Equal(c.fileModTime) {
+		names, err := readNamesFile(c.namesFile)
+		if err != nil {
+			c.log.WithError(err).Warn("No se pudo leer el archivo de nombres de procesos")
+		} else {
+			if len(names) == 0 {
+				c.log.WithField("file", c.namesFile).Warn("El archivo de nombres de procesos está vacío")
+			}
+			fileTargets := make([]target, 0, len(names))
+			for _, name := range names {
+				t, err := newTarget(name, c.matchMode)
+				if err != nil {
+					c.log.WithError(err).WithField("file", c.namesFile).Warn("Se ignora un nombre del archivo de nombres de procesos")
+					continue
+				}
+				fileTargets = append(fileTargets, t)
+			}
+			c.log.WithField("names", len(fileTargets)).Info("Archivo de nombres de procesos cargado")
+			c.fileTargets = fileTargets
+			c.fileModTime = info.ModTime()
+		}
+	}
+
+	// Combinar sin duplicados, conservando primero los nombres en línea
+	seen := make(map[string]bool, len(c.targets)+len(c.fileTargets))
+	var targets []target
+	for _, t := range append(append([]target{}, c.targets...), c.fileTargets...) {
+		if !seen[t.name] {
+			seen[t.name] = true
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// readNamesFile lee un nombre por línea, ignorando líneas vacías y comentarios (#)
+func readNamesFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names, scanner.Err()
+}
+
 // Name devuelve el nombre de este colector
 func (c *ProcessCollector) Name() string {
 	return "process"
@@ -115,3 +370,44 @@ func (c *ProcessCollector) Name() string {
 func (c *ProcessCollector) GetInterval() time.Duration {
 	return c.interval
 }
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *ProcessCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por cada proceso monitoreado
+func (c *ProcessCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "cpu_percent", Type: collector.Gauge, Unit: collector.UnitPercent},
+		{Name: "memory_percent", Type: collector.Gauge, Unit: collector.UnitPercent},
+		{Name: "memory_rss_bytes", Type: collector.Gauge, Unit: collector.UnitBytes},
+		{Name: "num_threads", Type: collector.Gauge, Unit: collector.UnitCount},
+		{Name: "process_count", Type: collector.Gauge, Unit: collector.UnitCount, Description: "PIDs sumados en la entrada (solo con aggregate)."},
+	}
+}
+
+// compact reduce el tamaño del reporte: conserva solo los top-N PIDs de cada
+// nombre según el criterio configurado y redondea los porcentajes.
+func (c *ProcessCollector) compact(monitored map[string][]ProcessInfo) {
+	for name, infos := range monitored {
+		if c.topN > 0 && len(infos) > c.topN {
+			sort.Slice(infos, func(i, j int) bool {
+				if c.topBy == "memory" {
+					return infos[i].MemoryRSS > infos[j].MemoryRSS
+				}
+				return infos[i].CPUPercent > infos[j].CPUPercent
+			})
+			infos = infos[:c.topN]
+		}
+
+		if c.floatPrecision >= 0 {
+			scale := math.Pow(10, float64(c.floatPrecision))
+			for i := range infos {
+				infos[i].CPUPercent = math.Round(infos[i].CPUPercent*scale) / scale
+				infos[i].MemoryPercent = float32(math.Round(float64(infos[i].MemoryPercent)*scale) / scale)
+			}
+		}
+		monitored[name] = infos
+	}
+}
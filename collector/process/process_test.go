@@ -0,0 +1,125 @@
+package process
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/atrox39/logtick/config"
+)
+
+// fakeProcess es un proceso falso cuyo tiempo de CPU acumulado fija el test
+type fakeProcess struct {
+	pid        int32
+	name       string
+	cpuSeconds float64
+}
+
+func (p *fakeProcess) PID() int32                                      { return p.pid }
+func (p *fakeProcess) NameWithContext(context.Context) (string, error) { return p.name, nil }
+func (p *fakeProcess) TimesWithContext(context.Context) (*cpu.TimesStat, error) {
+	return &cpu.TimesStat{User: p.cpuSeconds}, nil
+}
+func (p *fakeProcess) CreateTimeWithContext(context.Context) (int64, error)      { return 1000, nil }
+func (p *fakeProcess) MemoryPercentWithContext(context.Context) (float32, error) { return 1.5, nil }
+func (p *fakeProcess) MemoryInfoWithContext(context.Context) (*process.MemoryInfoStat, error) {
+	return &process.MemoryInfoStat{RSS: 1 << 20}, nil
+}
+func (p *fakeProcess) NumThreadsWithContext(context.Context) (int32, error) { return 4, nil }
+func (p *fakeProcess) StatusWithContext(context.Context) ([]string, error)  { return []string{"S"}, nil }
+
+// newFakeCollector crea un colector que monitorea "nginx" y lee los procesos de *procs
+func newFakeCollector(t *testing.T, procs *[]processHandle) *ProcessCollector {
+	t.Helper()
+	c, err := NewProcessCollector(&config.ProcessConfig{Enabled: true, ProcessNames: []string{"nginx"}})
+	if err != nil {
+		t.Fatalf("NewProcessCollector: %v", err)
+	}
+	c.source = func(context.Context) ([]processHandle, error) { return *procs, nil }
+	return c
+}
+
+func collectProcesses(t *testing.T, c *ProcessCollector) []ProcessInfo {
+	t.Helper()
+	data, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	return data.(*ProcessMetrics).MonitoredProcesses["nginx"]
+}
+
+func TestCollectCPUDeltaOnSecondCollection(t *testing.T) {
+	worker := &fakeProcess{pid: 10, name: "nginx", cpuSeconds: 5}
+	procs := []processHandle{worker}
+	c := newFakeCollector(t, &procs)
+
+	first := collectProcesses(t, c)
+	if len(first) != 1 || first[0].CPUPercent != 0 {
+		t.Fatalf("primera recolección: se esperaba un proceso con 0%% de CPU, se obtuvo %+v", first)
+	}
+
+	worker.cpuSeconds += 0.5
+	second := collectProcesses(t, c)
+	if len(second) != 1 || second[0].CPUPercent <= 0 {
+		t.Fatalf("segunda recolección: se esperaba un uso de CPU mayor que 0, se obtuvo %+v", second)
+	}
+}
+
+func TestCollectPrunesVanishedPIDs(t *testing.T) {
+	procs := []processHandle{
+		&fakeProcess{pid: 10, name: "nginx", cpuSeconds: 1},
+		&fakeProcess{pid: 11, name: "nginx", cpuSeconds: 1},
+		&fakeProcess{pid: 12, name: "sshd", cpuSeconds: 1},
+	}
+	c := newFakeCollector(t, &procs)
+
+	collectProcesses(t, c)
+	if len(c.cpuSamples) != 2 {
+		t.Fatalf("se esperaban 2 PIDs en la caché, hay %d", len(c.cpuSamples))
+	}
+
+	procs = procs[:1] // El PID 11 termina
+	got := collectProcesses(t, c)
+	if len(got) != 1 || got[0].PID != 10 {
+		t.Fatalf("se esperaba solo el PID 10, se obtuvo %+v", got)
+	}
+	if _, ok := c.cpuSamples[11]; ok || len(c.cpuSamples) != 1 {
+		t.Fatalf("la caché debía quedarse solo con el PID 10, contiene %v", c.cpuSamples)
+	}
+}
+
+func TestCompactFloatPrecision(t *testing.T) {
+	zero, two := 0, 2
+	tests := []struct {
+		name      string
+		precision *int
+		wantCPU   float64
+		wantMem   float32
+	}{
+		{"sin definir no redondea", nil, 12.3456, 1.5678},
+		{"cero redondea a enteros", &zero, 12, 2},
+		{"dos decimales", &two, 12.35, 1.57},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewProcessCollector(&config.ProcessConfig{
+				Enabled:        true,
+				ProcessNames:   []string{"nginx"},
+				FloatPrecision: tt.precision,
+			})
+			if err != nil {
+				t.Fatalf("NewProcessCollector: %v", err)
+			}
+			monitored := map[string][]ProcessInfo{
+				"nginx": {{PID: 1, CPUPercent: 12.3456, MemoryPercent: 1.5678}},
+			}
+			c.compact(monitored)
+			got := monitored["nginx"][0]
+			if got.CPUPercent != tt.wantCPU || got.MemoryPercent != tt.wantMem {
+				t.Errorf("cpu=%v mem=%v, se esperaba cpu=%v mem=%v", got.CPUPercent, got.MemoryPercent, tt.wantCPU, tt.wantMem)
+			}
+		})
+	}
+}
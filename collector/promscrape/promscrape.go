@@ -0,0 +1,220 @@
+package promscrape
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector" // Importa el paquete collector para la interfaz
+	"github.com/atrox39/logtick/collector/filter"
+	"github.com/atrox39/logtick/collector/httpclient"
+	"github.com/atrox39/logtick/config"
+)
+
+// acceptHeader pide el formato de texto, el único que interpreta el parser
+const acceptHeader = "text/plain;version=0.0.4"
+
+// Sample es un valor de una familia. Las familias histogram y summary se expanden en
+// varias muestras (_bucket, _sum, _count), como en el formato de exposición.
+type Sample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// Family contiene las muestras de una familia de métricas
+type Family struct {
+	Type    string   `json:"type"` // counter, gauge, summary, histogram o untyped
+	Samples []Sample `json:"samples"`
+}
+
+// PromScrapeMetrics contiene las familias seleccionadas del endpoint scrapeado
+type PromScrapeMetrics struct {
+	Families map[string]Family `json:"families"` // Mapa por nombre de familia
+}
+
+// PromScrapeCollector implementa la interfaz Collector para endpoints /metrics de terceros
+type PromScrapeCollector struct {
+	client      *http.Client
+	url         string
+	username    string
+	password    string
+	bearerToken string
+	allow       *filter.Matcher // Familias a incluir (vacío = todas)
+	interval    time.Duration
+	log         *logrus.Entry // Logger para este colector
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "promscrape",
+		Enabled: func(cfg *config.Config) bool { return cfg.PromScrape != nil && cfg.PromScrape.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewPromScrapeCollector(cfg.PromScrape)
+		},
+	})
+}
+
+// NewPromScrapeCollector crea una nueva instancia de PromScrapeCollector
+func NewPromScrapeCollector(cfg *config.PromScrapeConfig) (*PromScrapeCollector, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("URL de scrape no puede estar vacía")
+	}
+	allow, err := filter.Compile(cfg.Metrics)
+	if err != nil {
+		return nil, fmt.Errorf("filtro de métricas inválido: %w", err)
+	}
+	return &PromScrapeCollector{
+		client:      httpclient.New(10 * time.Second),
+		url:         cfg.URL,
+		username:    cfg.Username,
+		password:    cfg.Password,
+		bearerToken: cfg.BearerToken,
+		allow:       allow,
+		interval:    time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:         logrus.WithField("collector", "promscrape"),
+	}, nil
+}
+
+// Collect scrapea el endpoint y conserva las familias permitidas
+func (c *PromScrapeCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear solicitud HTTP de scrape: %w", err)
+	}
+	req.Header.Set("Accept", acceptHeader)
+	switch {
+	case c.bearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al realizar solicitud HTTP a '%s': %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta inesperada del endpoint de métricas: %s", resp.Status)
+	}
+
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error al parsear el formato de exposición: %w", err)
+	}
+
+	metrics := &PromScrapeMetrics{Families: make(map[string]Family)}
+	for name, mf := range parsed {
+		if !c.allow.Match(name) {
+			continue
+		}
+		metrics.Families[name] = convertFamily(mf)
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"families_total":    len(parsed),
+		"families_reported": len(metrics.Families),
+	}).Debug("Métricas de Prometheus scrapeadas")
+
+	return metrics, nil
+}
+
+// convertFamily convierte una familia del modelo de Prometheus en muestras planas
+func convertFamily(mf *dto.MetricFamily) Family {
+	name := mf.GetName()
+	family := Family{Type: typeName(mf.GetType())}
+
+	for _, m := range mf.GetMetric() {
+		labels := make(map[string]string, len(m.GetLabel()))
+		for _, lp := range m.GetLabel() {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		add := func(suffix string, value float64, extra ...string) {
+			sampleLabels := labels
+			if len(extra) == 2 {
+				sampleLabels = make(map[string]string, len(labels)+1)
+				for k, v := range labels {
+					sampleLabels[k] = v
+				}
+				sampleLabels[extra[0]] = extra[1]
+			}
+			family.Samples = append(family.Samples, Sample{Name: name + suffix, Labels: sampleLabels, Value: value})
+		}
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			add("", m.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			add("", m.GetGauge().GetValue())
+		case dto.MetricType_SUMMARY:
+			s := m.GetSummary()
+			for _, q := range s.GetQuantile() {
+				add("", q.GetValue(), "quantile", strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64))
+			}
+			add("_sum", s.GetSampleSum())
+			add("_count", float64(s.GetSampleCount()))
+		case dto.MetricType_HISTOGRAM:
+			h := m.GetHistogram()
+			for _, b := range h.GetBucket() {
+				add("_bucket", float64(b.GetCumulativeCount()), "le", strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64))
+			}
+			add("_sum", h.GetSampleSum())
+			add("_count", float64(h.GetSampleCount()))
+		default:
+			add("", m.GetUntyped().GetValue())
+		}
+	}
+	return family
+}
+
+// typeName devuelve el tipo de familia en minúsculas, como en el formato de exposición
+func typeName(t dto.MetricType) string {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return "counter"
+	case dto.MetricType_GAUGE:
+		return "gauge"
+	case dto.MetricType_SUMMARY:
+		return "summary"
+	case dto.MetricType_HISTOGRAM:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// Name devuelve el nombre de este colector
+func (c *PromScrapeCollector) Name() string {
+	return "promscrape"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *PromScrapeCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *PromScrapeCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector.
+// El tipo real de cada familia viaja en el propio reporte (campo "type").
+func (c *PromScrapeCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "value", Type: collector.Gauge, Unit: collector.UnitNone, Description: "Valor de la muestra scrapeada; ver el tipo de su familia."},
+	}
+}
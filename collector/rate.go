@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateTracker convierte contadores acumulados en tasas por segundo. Guarda la última lectura de
+// cada contador, identificado por una clave (ej. "Queries" o "eth0/bytes_sent"), con su instante.
+// No es seguro para uso concurrente: cada colector usa el suyo desde Collect.
+type RateTracker struct {
+	samples map[string]rateSample
+	log     *logrus.Entry // Registra los reinicios de contadores; puede ser nil
+}
+
+// rateSample es la última lectura de un contador
+type rateSample struct {
+	value uint64
+	at    time.Time
+}
+
+// NewRateTracker crea un RateTracker vacío. log, normalmente el del colector, registra los
+// reinicios de contadores detectados.
+func NewRateTracker(log *logrus.Entry) *RateTracker {
+	return &RateTracker{samples: make(map[string]rateSample), log: log}
+}
+
+// Rate guarda la lectura value del contador key tomada en now y devuelve su tasa por segundo desde
+// la lectura anterior. Devuelve 0 en la primera lectura y si no pasó tiempo. En todos los casos la
+// lectura queda como referencia para la siguiente.
+//
+// Un valor menor que el anterior es un reinicio del contador (ej. MySQL o Nginx se reiniciaron y
+// cuentan desde cero): restar daría una tasa negativa o, con enteros sin signo, enorme, así que el
+// intervalo del reinicio se reporta como 0 y la tasa se calcula de nuevo desde la siguiente lectura.
+func (t *RateTracker) Rate(key string, value uint64, now time.Time) float64 {
+	prev, ok := t.samples[key]
+	t.samples[key] = rateSample{value: value, at: now}
+	if !ok {
+		return 0
+	}
+	if value < prev.value {
+		if t.log != nil {
+			t.log.WithFields(logrus.Fields{
+				"counter":  key,
+				"previous": prev.value,
+				"current":  value,
+			}).Info("Contador reiniciado (¿se reinició el servicio?). Su tasa de este intervalo se reporta como 0.")
+		}
+		return 0
+	}
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(value-prev.value) / elapsed
+}
+
+// Sweep descarta los contadores que no se leyeron en now, para que las claves que desaparecen
+// (ej. una interfaz de red o un contenedor eliminados) no se acumulen
+func (t *RateTracker) Sweep(now time.Time) {
+	for key, s := range t.samples {
+		if !s.at.Equal(now) {
+			delete(t.samples, key)
+		}
+	}
+}
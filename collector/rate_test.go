@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateTrackerRate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	type sample struct {
+		value uint64
+		after time.Duration // Desde start
+		want  float64
+	}
+	tests := []struct {
+		name    string
+		samples []sample
+	}{
+		{
+			name:    "primera lectura",
+			samples: []sample{{value: 500, after: 0, want: 0}},
+		},
+		{
+			name: "incremento constante",
+			samples: []sample{
+				{value: 100, after: 0, want: 0},
+				{value: 200, after: 10 * time.Second, want: 10},
+				{value: 250, after: 15 * time.Second, want: 10},
+			},
+		},
+		{
+			name: "reinicio del contador",
+			samples: []sample{
+				{value: 1000, after: 0, want: 0},
+				{value: 1100, after: 10 * time.Second, want: 10},
+				{value: 8, after: 20 * time.Second, want: 0},
+				{value: 28, after: 30 * time.Second, want: 2}, // La lectura del reinicio es la nueva referencia
+			},
+		},
+		{
+			name: "sin tiempo transcurrido",
+			samples: []sample{
+				{value: 100, after: time.Second, want: 0},
+				{value: 200, after: time.Second, want: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewRateTracker(nil)
+			for i, s := range tt.samples {
+				if got := tracker.Rate("requests", s.value, start.Add(s.after)); got != s.want {
+					t.Errorf("lectura %d (%d): Rate = %v, se esperaba %v", i, s.value, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateTrackerSweep(t *testing.T) {
+	tracker := NewRateTracker(nil)
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.Rate("eth0/bytes_sent", 100, first)
+	tracker.Rate("eth1/bytes_sent", 100, first)
+
+	second := first.Add(10 * time.Second)
+	tracker.Rate("eth0/bytes_sent", 200, second)
+	tracker.Sweep(second)
+
+	if _, ok := tracker.samples["eth1/bytes_sent"]; ok {
+		t.Error("Sweep no descartó la clave que no se leyó")
+	}
+	if _, ok := tracker.samples["eth0/bytes_sent"]; !ok {
+		t.Error("Sweep descartó una clave leída")
+	}
+	// Una clave descartada vuelve a empezar como primera lectura
+	if got := tracker.Rate("eth1/bytes_sent", 300, second.Add(10*time.Second)); got != 0 {
+		t.Errorf("Rate tras Sweep = %v, se esperaba 0", got)
+	}
+}
@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/config"
+)
+
+// Registration describe cómo construir un colector a partir de la configuración.
+// Cada paquete de colector se registra a sí mismo en su init().
+type Registration struct {
+	Name    string                                      // Coincide con Collector.Name()
+	Enabled func(cfg *config.Config) bool               // Indica si el colector está habilitado en la configuración
+	New     func(cfg *config.Config) (Collector, error) // Construye el colector; solo se llama si Enabled devuelve true
+}
+
+var (
+	registryMu    sync.RWMutex
+	registrations []Registration
+)
+
+// Register añade un colector al registro. Entra en pánico si el nombre ya está registrado,
+// ya que indica un error de programación.
+func Register(r Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, existing := range registrations {
+		if existing.Name == r.Name {
+			panic(fmt.Sprintf("colector '%s' registrado dos veces", r.Name))
+		}
+	}
+	registrations = append(registrations, r)
+}
+
+// Registrations devuelve los colectores registrados en orden de registro
+func Registrations() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Registration(nil), registrations...)
+}
+
+// BuildCollectors instancia todos los colectores habilitados en la configuración.
+// Un colector que no se puede inicializar se registra en el log y se omite.
+func BuildCollectors(cfg *config.Config) []Collector {
+	var collectors []Collector
+	for _, r := range Registrations() {
+		if !r.Enabled(cfg) {
+			continue
+		}
+		log := logrus.WithField("collector", r.Name)
+		c, err := r.New(cfg)
+		if err != nil {
+			log.WithError(err).Error("No se pudo inicializar el colector.")
+			continue
+		}
+		collectors = append(collectors, c)
+		log.Info("Colector inicializado.")
+	}
+	return collectors
+}
+
+// Registro del colector de sistema, habilitado salvo que se desactive explícitamente
+func init() {
+	Register(Registration{
+		Name:    "system",
+		Enabled: func(cfg *config.Config) bool { return cfg.System.IsEnabled() },
+		New: func(cfg *config.Config) (Collector, error) {
+			return NewSystemCollector(cfg)
+		},
+	})
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/atrox39/logtick/config"
+)
+
+// fakeCollector es un colector mínimo para probar el registro
+type fakeCollector struct{}
+
+func (fakeCollector) Name() string                                { return "fake" }
+func (fakeCollector) GetInterval() time.Duration                  { return time.Second }
+func (fakeCollector) Collect(context.Context) (MetricData, error) { return nil, nil }
+func (fakeCollector) Close() error                                { return nil }
+
+// withFakeRegistration registra el colector falso durante el test y restaura el registro al terminar
+func withFakeRegistration(t *testing.T, built *int) {
+	t.Helper()
+	registryMu.Lock()
+	saved := append([]Registration(nil), registrations...)
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registrations = saved
+		registryMu.Unlock()
+	})
+
+	Register(Registration{
+		Name:    "fake",
+		Enabled: func(cfg *config.Config) bool { return cfg.AgentName == "con-fake" },
+		New: func(cfg *config.Config) (Collector, error) {
+			*built++
+			return fakeCollector{}, nil
+		},
+	})
+}
+
+func TestBuildCollectorsOnlyEnabled(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		agentName string
+		wantBuilt bool
+	}{
+		{agentName: "con-fake", wantBuilt: true},
+		{agentName: "sin-fake", wantBuilt: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agentName, func(t *testing.T) {
+			built := 0
+			withFakeRegistration(t, &built)
+			// El colector de sistema se deshabilita para que solo cuente el falso
+			cfg := &config.Config{AgentName: tt.agentName, System: &config.SystemConfig{Enabled: &disabled}}
+
+			collectors := BuildCollectors(cfg)
+
+			if tt.wantBuilt {
+				if built != 1 || len(collectors) != 1 || collectors[0].Name() != "fake" {
+					t.Fatalf("se esperaba construir solo el colector falso, New llamado %d veces, colectores: %v", built, collectors)
+				}
+				return
+			}
+			if built != 0 || len(collectors) != 0 {
+				t.Fatalf("el colector deshabilitado no debía construirse, New llamado %d veces, colectores: %v", built, collectors)
+			}
+		})
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	built := 0
+	withFakeRegistration(t, &built)
+	defer func() {
+		if recover() == nil {
+			t.Error("registrar dos veces el mismo nombre debía entrar en pánico")
+		}
+	}()
+	Register(Registration{Name: "fake"})
+}
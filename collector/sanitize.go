@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"math"
+	"reflect"
+)
+
+// SanitizeFloats recorre las métricas y reemplaza los valores NaN/±Inf, que json.Marshal rechaza.
+// Con omit, los punteros se ponen a nil y las entradas de mapas se eliminan; los campos float
+// simples siempre se ponen a cero. Devuelve el número de valores corregidos.
+func SanitizeFloats(data MetricData, omit bool) int {
+	return sanitizeValue(reflect.ValueOf(data), omit)
+}
+
+// isNonFinite indica si v es un float NaN o infinito
+func isNonFinite(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return math.IsNaN(f) || math.IsInf(f, 0)
+	}
+	return false
+}
+
+// sanitizeValue corrige v en sitio cuando es direccionable
+func sanitizeValue(v reflect.Value, omit bool) int {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if isNonFinite(v) && v.CanSet() {
+			v.SetFloat(0)
+			return 1
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			return 0
+		}
+		if omit && isNonFinite(v.Elem()) && v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+			return 1
+		}
+		return sanitizeValue(v.Elem(), omit)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return sanitizeValue(v.Elem(), omit)
+	case reflect.Struct:
+		fixed := 0
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fixed += sanitizeValue(v.Field(i), omit)
+			}
+		}
+		return fixed
+	case reflect.Slice, reflect.Array:
+		fixed := 0
+		for i := 0; i < v.Len(); i++ {
+			fixed += sanitizeValue(v.Index(i), omit)
+		}
+		return fixed
+	case reflect.Map:
+		// Los valores de un mapa no son direccionables: se copian, se corrigen y se reescriben
+		fixed := 0
+		iter := v.MapRange()
+		for iter.Next() {
+			key, val := iter.Key(), iter.Value()
+			if isNonFinite(val) {
+				if omit {
+					v.SetMapIndex(key, reflect.Value{})
+				} else {
+					v.SetMapIndex(key, reflect.Zero(val.Type()))
+				}
+				fixed++
+				continue
+			}
+			copied := reflect.New(val.Type()).Elem()
+			copied.Set(val)
+			if n := sanitizeValue(copied, omit); n > 0 {
+				v.SetMapIndex(key, copied)
+				fixed += n
+			}
+		}
+		return fixed
+	}
+	return 0
+}
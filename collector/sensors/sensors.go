@@ -0,0 +1,148 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// hwmonPath es donde Linux expone los sensores de hardware (incluidos ventiladores)
+const hwmonPath = "/sys/class/hwmon"
+
+// Temperature contiene la lectura de un sensor de temperatura
+type Temperature struct {
+	Celsius  float64 `json:"celsius"`
+	High     float64 `json:"high_celsius,omitempty"`
+	Critical float64 `json:"critical_celsius,omitempty"`
+}
+
+// SensorsMetrics contiene las lecturas de sensores de hardware.
+// En hosts sin sensores ambos mapas están vacíos.
+type SensorsMetrics struct {
+	Temperatures map[string]Temperature `json:"temperatures"` // Mapa por clave de sensor
+	FansRPM      map[string]uint64      `json:"fans_rpm"`     // Mapa por chip/ventilador (solo Linux)
+}
+
+// SensorsCollector implementa la interfaz Collector para sensores de temperatura y ventiladores
+type SensorsCollector struct {
+	interval time.Duration
+	log      *logrus.Entry
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "sensors",
+		Enabled: func(cfg *config.Config) bool { return cfg.Sensors != nil && cfg.Sensors.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewSensorsCollector(cfg.Sensors)
+		},
+	})
+}
+
+// NewSensorsCollector crea una nueva instancia de SensorsCollector
+func NewSensorsCollector(cfg *config.SensorsConfig) (*SensorsCollector, error) {
+	return &SensorsCollector{
+		interval: time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:      logrus.WithField("collector", "sensors"),
+	}, nil
+}
+
+// Collect recolecta las temperaturas y velocidades de ventiladores disponibles
+func (c *SensorsCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	metrics := &SensorsMetrics{
+		Temperatures: make(map[string]Temperature),
+		FansRPM:      make(map[string]uint64),
+	}
+
+	temps, err := host.SensorsTemperaturesWithContext(ctx)
+	if err != nil {
+		// Las advertencias indican sensores individuales ilegibles; el resto de lecturas es válido
+		var warns *host.Warnings
+		if !errors.As(err, &warns) {
+			c.log.WithError(err).Debug("Sensores de temperatura no disponibles en este host")
+		}
+	}
+	for _, t := range temps {
+		metrics.Temperatures[t.SensorKey] = Temperature{
+			Celsius:  t.Temperature,
+			High:     t.High,
+			Critical: t.Critical,
+		}
+	}
+
+	if err := readFans(metrics.FansRPM); err != nil {
+		return nil, err
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"temperatures": len(metrics.Temperatures),
+		"fans":         len(metrics.FansRPM),
+	}).Debug("Métricas de sensores recolectadas")
+
+	return metrics, nil
+}
+
+// readFans lee las velocidades de ventiladores de hwmon. Si hwmon no existe no hay ventiladores que reportar.
+func readFans(out map[string]uint64) error {
+	inputs, err := filepath.Glob(filepath.Join(hwmonPath, "hwmon*", "fan*_input"))
+	if err != nil {
+		return fmt.Errorf("error al buscar ventiladores en hwmon: %w", err)
+	}
+
+	for _, input := range inputs {
+		data, err := os.ReadFile(input)
+		if err != nil {
+			continue // Sensor ilegible, se ignora
+		}
+		rpm, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		dir := filepath.Dir(input)
+		chip := filepath.Base(dir)
+		if name, err := os.ReadFile(filepath.Join(dir, "name")); err == nil {
+			chip = strings.TrimSpace(string(name))
+		}
+		fan := strings.TrimSuffix(filepath.Base(input), "_input")
+		out[chip+"_"+fan] = rpm
+	}
+	return nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *SensorsCollector) Name() string {
+	return "sensors"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *SensorsCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *SensorsCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *SensorsCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "celsius", Type: collector.Gauge, Unit: collector.UnitCelsius},
+		{Name: "high_celsius", Type: collector.Gauge, Unit: collector.UnitCelsius},
+		{Name: "critical_celsius", Type: collector.Gauge, Unit: collector.UnitCelsius},
+		{Name: "fans_rpm", Type: collector.Gauge, Unit: collector.UnitNone, Description: "Revoluciones por minuto."},
+	}
+}
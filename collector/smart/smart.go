@@ -0,0 +1,204 @@
+package smart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// Bits del código de salida de smartctl que indican que no se pudo leer el dispositivo.
+// Los bits superiores (disco fallando, errores en el log, etc.) no impiden parsear la salida.
+const (
+	exitCommandLineError = 1 << 0
+	exitDeviceOpenFailed = 1 << 1
+)
+
+// smartctlTimeout limita la duración de cada invocación de smartctl
+const smartctlTimeout = 30 * time.Second
+
+// DeviceHealth contiene el estado SMART de un dispositivo
+type DeviceHealth struct {
+	Model              string `json:"model,omitempty"`
+	Passed             bool   `json:"passed"` // Resultado global del autodiagnóstico SMART
+	TemperatureCelsius int    `json:"temperature_celsius"`
+	PowerOnHours       uint64 `json:"power_on_hours"`
+	ReallocatedSectors uint64 `json:"reallocated_sectors"`
+	MediaErrors        uint64 `json:"media_errors,omitempty"` // Solo NVMe
+	Error              string `json:"error,omitempty"`        // Error al leer el dispositivo en esta ronda
+}
+
+// SmartMetrics contiene el estado SMART de los dispositivos configurados
+type SmartMetrics struct {
+	Devices map[string]DeviceHealth `json:"devices"` // Mapa por ruta del dispositivo
+}
+
+// smartctlOutput es el subconjunto de la salida JSON de `smartctl -j` que nos interesa
+type smartctlOutput struct {
+	ModelName   string `json:"model_name"`
+	SmartStatus struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime struct {
+		Hours uint64 `json:"hours"`
+	} `json:"power_on_time"`
+	ATASmartAttributes struct {
+		Table []struct {
+			ID  int `json:"id"`
+			Raw struct {
+				Value uint64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeHealth struct {
+		MediaErrors uint64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// reallocatedSectorsID es el atributo ATA "Reallocated_Sector_Ct"
+const reallocatedSectorsID = 5
+
+// SmartCollector implementa la interfaz Collector para la salud SMART de discos
+type SmartCollector struct {
+	smartctlPath string
+	devices      []string
+	interval     time.Duration
+	log          *logrus.Entry
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "smart",
+		Enabled: func(cfg *config.Config) bool { return cfg.Smart != nil && cfg.Smart.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewSmartCollector(cfg.Smart)
+		},
+	})
+}
+
+// NewSmartCollector crea una nueva instancia de SmartCollector.
+// Falla si smartctl no está instalado o si no hay permisos para leer los dispositivos.
+func NewSmartCollector(cfg *config.SmartConfig) (*SmartCollector, error) {
+	if len(cfg.Devices) == 0 {
+		return nil, fmt.Errorf("se requiere al menos un dispositivo para el colector SMART")
+	}
+
+	binary := cfg.SmartctlPath
+	if binary == "" {
+		binary = "smartctl"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("no se encontró smartctl (%s): %w", binary, err)
+	}
+
+	c := &SmartCollector{
+		smartctlPath: path,
+		devices:      cfg.Devices,
+		interval:     time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:          logrus.WithField("collector", "smart"),
+	}
+
+	// Verificar que podemos abrir el primer dispositivo (normalmente requiere root)
+	if _, err := c.readDevice(context.Background(), cfg.Devices[0]); err != nil {
+		return nil, fmt.Errorf("no se pudo leer SMART de '%s' (¿permisos?): %w", cfg.Devices[0], err)
+	}
+
+	return c, nil
+}
+
+// readDevice invoca smartctl para un dispositivo y parsea su salida
+func (c *SmartCollector) readDevice(ctx context.Context, device string) (*DeviceHealth, error) {
+	ctx, cancel := context.WithTimeout(ctx, smartctlTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, c.smartctlPath, "-j", "-H", "-A", "-i", device).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("error al ejecutar smartctl: %w", err)
+		}
+		// smartctl usa el código de salida como máscara de bits
+		if code := exitErr.ExitCode(); code&(exitCommandLineError|exitDeviceOpenFailed) != 0 {
+			return nil, fmt.Errorf("smartctl terminó con código %d", code)
+		}
+	}
+
+	var parsed smartctlOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, fmt.Errorf("error al parsear la salida de smartctl: %w", err)
+	}
+
+	health := &DeviceHealth{
+		Model:              parsed.ModelName,
+		Passed:             parsed.SmartStatus.Passed,
+		TemperatureCelsius: parsed.Temperature.Current,
+		PowerOnHours:       parsed.PowerOnTime.Hours,
+		MediaErrors:        parsed.NVMeHealth.MediaErrors,
+	}
+	for _, attr := range parsed.ATASmartAttributes.Table {
+		if attr.ID == reallocatedSectorsID {
+			health.ReallocatedSectors = attr.Raw.Value
+		}
+	}
+	return health, nil
+}
+
+// Collect recolecta el estado SMART de cada dispositivo configurado.
+// Un dispositivo ilegible se reporta con su error sin invalidar al resto.
+func (c *SmartCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	metrics := &SmartMetrics{Devices: make(map[string]DeviceHealth, len(c.devices))}
+
+	for _, device := range c.devices {
+		health, err := c.readDevice(ctx, device)
+		if err != nil {
+			c.log.WithError(err).WithField("device", device).Warn("Error al leer SMART del dispositivo")
+			metrics.Devices[device] = DeviceHealth{Error: err.Error()}
+			continue
+		}
+		if !health.Passed {
+			c.log.WithField("device", device).Warn("El dispositivo reporta fallo en el autodiagnóstico SMART")
+		}
+		metrics.Devices[device] = *health
+	}
+
+	c.log.WithField("devices", len(metrics.Devices)).Debug("Métricas SMART recolectadas")
+	return metrics, nil
+}
+
+// Name devuelve el nombre de este colector
+func (c *SmartCollector) Name() string {
+	return "smart"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *SmartCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *SmartCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por cada dispositivo
+func (c *SmartCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "passed", Type: collector.Gauge, Unit: collector.UnitNone, Description: "1 si el autodiagnóstico SMART es correcto."},
+		{Name: "temperature_celsius", Type: collector.Gauge, Unit: collector.UnitCelsius},
+		{Name: "power_on_hours", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "reallocated_sectors", Type: collector.Counter, Unit: collector.UnitCount},
+		{Name: "media_errors", Type: collector.Counter, Unit: collector.UnitCount},
+	}
+}
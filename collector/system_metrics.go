@@ -1,12 +1,20 @@
 package collector
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"runtime"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
+	gnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/sirupsen/logrus"
 
+	"github.com/atrox39/logtick/collector/filter"
 	"github.com/atrox39/logtick/config" // Importar la configuración de tu proyecto
 )
 
@@ -16,56 +24,292 @@ type MetricData interface{}
 type Collector interface {
 	Name() string
 	GetInterval() time.Duration
-	Collect() (MetricData, error)
+	Collect(ctx context.Context) (MetricData, error) // ctx se cancela al apagar el agente o detener el colector
+	Close() error                                    // Libera conexiones u otros recursos al apagar el agente
 }
 
 // SystemMetrics contiene las métricas recolectadas del sistema.
 // Ya no incluirá AgentID, AgentName ni Timestamp, ya que se manejarán
 // a nivel de "AgentReport" antes del envío al backend.
+// La memoria se reporta siempre en bytes y, además, en la unidad configurada (memory_unit) si no
+// es bytes; el nombre del campo JSON indica siempre la unidad.
 type SystemMetrics struct {
-	CPUPercent float64 `json:"cpu_percent"`
-	MemoryUsed uint64  `json:"memory_used_mb"` // En MB
-	MemoryFree uint64  `json:"memory_free_mb"` // En MB
+	CPUPercent float64   `json:"cpu_percent"`
+	PerCore    []float64 `json:"per_core,omitempty"` // Uso por núcleo (solo con cpu_per_core)
+
+	// Carga media a 1, 5 y 15 minutos; se omiten donde el sistema no la ofrece (Windows)
+	Load1  *float64 `json:"load1,omitempty"`
+	Load5  *float64 `json:"load5,omitempty"`
+	Load15 *float64 `json:"load15,omitempty"`
+
+	MemoryUsedBytes      *uint64 `json:"memory_used_bytes,omitempty"`
+	MemoryFreeBytes      *uint64 `json:"memory_free_bytes,omitempty"`
+	MemoryTotalBytes     uint64  `json:"memory_total_bytes"`
+	MemoryAvailableBytes uint64  `json:"memory_available_bytes"` // Memoria que pueden usar los procesos sin recurrir a swap
+	MemoryUsedPercent    float64 `json:"memory_used_percent"`
+
+	MemoryUsedKB *float64 `json:"memory_used_kb,omitempty"`
+	MemoryFreeKB *float64 `json:"memory_free_kb,omitempty"`
+	MemoryUsedMB *float64 `json:"memory_used_mb,omitempty"`
+	MemoryFreeMB *float64 `json:"memory_free_mb,omitempty"`
+	MemoryUsedGB *float64 `json:"memory_used_gb,omitempty"`
+	MemoryFreeGB *float64 `json:"memory_free_gb,omitempty"`
+
+	Network map[string]NetworkInterface `json:"network"` // Mapa por nombre de interfaz
+
+	Disks map[string]DiskUsage `json:"disks"` // Mapa por punto de montaje
+}
+
+// DiskUsage contiene el uso de espacio de un punto de montaje.
+type DiskUsage struct {
+	Fstype      string  `json:"fstype"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	UsedBytes   uint64  `json:"used_bytes"`
+	FreeBytes   uint64  `json:"free_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// NetworkInterface contiene los contadores de tráfico de una interfaz de red.
+// Los totales son acumulados desde el arranque; las tasas se calculan contra la muestra anterior.
+type NetworkInterface struct {
+	BytesSent            uint64  `json:"bytes_sent"`
+	BytesRecv            uint64  `json:"bytes_recv"`
+	PacketsSent          uint64  `json:"packets_sent"`
+	PacketsRecv          uint64  `json:"packets_recv"`
+	Errors               uint64  `json:"errors"` // Errores de entrada y salida
+	BytesSentPerSecond   float64 `json:"bytes_sent_per_second"`
+	BytesRecvPerSecond   float64 `json:"bytes_recv_per_second"`
+	PacketsSentPerSecond float64 `json:"packets_sent_per_second"`
+	PacketsRecvPerSecond float64 `json:"packets_recv_per_second"`
+}
+
+// memoryUnits relaciona cada valor de memory_unit con su divisor, sufijo de campo y unidad de metadatos
+var memoryUnits = map[string]struct {
+	divisor float64
+	suffix  string
+	unit    Unit
+}{
+	"bytes": {1, "bytes", UnitBytes},
+	"kb":    {1 << 10, "kb", UnitKilobytes},
+	"mb":    {1 << 20, "mb", UnitMegabytes},
+	"gb":    {1 << 30, "gb", UnitGigabytes},
 }
 
 // SystemCollector implementa la interfaz Collector para métricas del sistema.
 type SystemCollector struct {
-	interval time.Duration
+	interval        time.Duration
+	mounts          *filter.Matcher // Puntos de montaje a reportar (disk_mounts; vacío = todos)
+	memoryUnit      string          // bytes, kb, mb o gb
+	excludeLoopback bool            // Omitir las interfaces de loopback en las métricas de red
+	interfaces      *filter.Matcher // Interfaces de red a reportar (network_interfaces; vacío = todas)
+	cpuWindow       time.Duration   // Ventana de muestreo de CPU (0 = desde la llamada anterior)
+	perCore         bool            // Reportar también el uso por núcleo
+
+	networkRates *RateTracker // Tasas de los contadores de red, por interfaz y contador
 }
 
 // NewSystemCollector crea una nueva instancia de SystemCollector.
-// Recibe la configuración global para obtener el intervalo.
-func NewSystemCollector(cfg *config.Config) *SystemCollector {
-	return &SystemCollector{
-		interval: time.Duration(cfg.IntervalSeconds) * time.Second,
+// Recibe la configuración global para obtener el intervalo (system.collection_interval_seconds
+// o, en su defecto, interval_seconds) y las opciones de la sección system. Falla si
+// network_interfaces o disk_mounts contienen un patrón inválido.
+func NewSystemCollector(cfg *config.Config) (*SystemCollector, error) {
+	sys := cfg.System
+	if sys == nil {
+		sys = &config.SystemConfig{}
+	}
+	unit := sys.MemoryUnit
+	if _, ok := memoryUnits[unit]; !ok {
+		unit = "bytes"
 	}
+	interval := cfg.IntervalSeconds
+	if sys.CollectionIntervalSeconds > 0 {
+		interval = sys.CollectionIntervalSeconds
+	}
+	interfaces, err := filter.Compile(sys.NetworkInterfaces)
+	if err != nil {
+		return nil, fmt.Errorf("network_interfaces inválido: %w", err)
+	}
+	mounts, err := filter.Compile(sys.DiskMounts)
+	if err != nil {
+		return nil, fmt.Errorf("disk_mounts inválido: %w", err)
+	}
+	return &SystemCollector{
+		interval:        time.Duration(interval) * time.Second,
+		mounts:          mounts,
+		memoryUnit:      unit,
+		excludeLoopback: sys.NetworkExcludeLoopback,
+		interfaces:      interfaces,
+		cpuWindow:       time.Duration(sys.CPUSampleWindowMs) * time.Millisecond,
+		perCore:         sys.CPUPerCore,
+		networkRates:    NewRateTracker(logrus.WithField("collector", "system")),
+	}, nil
 }
 
-// Collect recolecta métricas de CPU y memoria.
-// Implementa el método Collect() de la interfaz Collector.
-func (c *SystemCollector) Collect() (MetricData, error) {
-	// Obtener uso de CPU
-	cpuPercents, err := cpu.Percent(0, false)
+// Collect recolecta métricas de CPU, memoria, red y disco.
+// Implementa el método Collect de la interfaz Collector.
+func (c *SystemCollector) Collect(ctx context.Context) (MetricData, error) {
+	// Obtener uso de CPU. Con una ventana configurada, cpu.Percent bloquea durante ella (o hasta que
+	// se cancele ctx) y la lectura por núcleo se toma en paralelo para no duplicar la espera.
+	var perCore []float64
+	var perCoreErr error
+	done := make(chan struct{})
+	if c.perCore {
+		go func() {
+			defer close(done)
+			perCore, perCoreErr = cpu.PercentWithContext(ctx, c.cpuWindow, true)
+		}()
+	} else {
+		close(done)
+	}
+
+	cpuPercents, err := cpu.PercentWithContext(ctx, c.cpuWindow, false)
+	<-done
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener uso de CPU: %w", err)
 	}
+	if perCoreErr != nil {
+		return nil, fmt.Errorf("error al obtener uso de CPU por núcleo: %w", perCoreErr)
+	}
 	cpuPercent := cpuPercents[0]
 
 	// Obtener uso de memoria
-	vMem, err := mem.VirtualMemory()
+	vMem, err := mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener uso de memoria: %w", err)
 	}
 
-	metrics := &SystemMetrics{
-		CPUPercent: cpuPercent,
-		MemoryUsed: vMem.Used / 1024 / 1024,
-		MemoryFree: vMem.Free / 1024 / 1024,
+	metrics := &SystemMetrics{CPUPercent: cpuPercent, PerCore: perCore}
+	metrics.setMemory(c.memoryUnit, vMem.Used, vMem.Free)
+	metrics.MemoryTotalBytes = vMem.Total
+	metrics.MemoryAvailableBytes = vMem.Available
+	metrics.MemoryUsedPercent = vMem.UsedPercent
+	metrics.setLoad(ctx)
+
+	if metrics.Network, err = c.collectNetwork(ctx); err != nil {
+		return nil, err
 	}
 
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los puntos de montaje: %w", err)
+	}
+	metrics.Disks = collectDisks(partitions, c.mounts, func(path string) (*disk.UsageStat, error) {
+		return disk.UsageWithContext(ctx, path)
+	})
+
 	return metrics, nil
 }
 
+// collectDisks devuelve el uso de los puntos de montaje permitidos por mounts. Un punto de montaje
+// que no se puede leer (ej. sin permisos) se omite en lugar de invalidar toda la recolección.
+func collectDisks(partitions []disk.PartitionStat, mounts *filter.Matcher, usage func(path string) (*disk.UsageStat, error)) map[string]DiskUsage {
+	disks := make(map[string]DiskUsage, len(partitions))
+	for _, p := range partitions {
+		if !mounts.Match(p.Mountpoint) {
+			continue
+		}
+		u, err := usage(p.Mountpoint)
+		if err != nil {
+			continue
+		}
+		disks[p.Mountpoint] = DiskUsage{
+			Fstype:      p.Fstype,
+			TotalBytes:  u.Total,
+			UsedBytes:   u.Used,
+			FreeBytes:   u.Free,
+			UsedPercent: u.UsedPercent,
+		}
+	}
+	return disks
+}
+
+// collectNetwork lee los contadores por interfaz y calcula las tasas por segundo
+// contra la muestra anterior (cero en la primera recolección o tras un reinicio de contadores).
+func (c *SystemCollector) collectNetwork(ctx context.Context) (map[string]NetworkInterface, error) {
+	counters, err := gnet.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener contadores de red: %w", err)
+	}
+
+	var loopback map[string]bool
+	if c.excludeLoopback {
+		loopback = loopbackInterfaces()
+	}
+
+	now := time.Now()
+	network := make(map[string]NetworkInterface, len(counters))
+
+	for _, io := range counters {
+		if loopback[io.Name] || !c.interfaces.Match(io.Name) {
+			continue
+		}
+
+		iface := NetworkInterface{
+			BytesSent:   io.BytesSent,
+			BytesRecv:   io.BytesRecv,
+			PacketsSent: io.PacketsSent,
+			PacketsRecv: io.PacketsRecv,
+			Errors:      io.Errin + io.Errout,
+		}
+		iface.BytesSentPerSecond = c.networkRates.Rate(io.Name+"/bytes_sent", io.BytesSent, now)
+		iface.BytesRecvPerSecond = c.networkRates.Rate(io.Name+"/bytes_recv", io.BytesRecv, now)
+		iface.PacketsSentPerSecond = c.networkRates.Rate(io.Name+"/packets_sent", io.PacketsSent, now)
+		iface.PacketsRecvPerSecond = c.networkRates.Rate(io.Name+"/packets_recv", io.PacketsRecv, now)
+		network[io.Name] = iface
+	}
+
+	c.networkRates.Sweep(now)
+	return network, nil
+}
+
+// loopbackInterfaces devuelve los nombres de las interfaces de loopback del host
+func loopbackInterfaces() map[string]bool {
+	names := map[string]bool{"lo": true} // Por si la enumeración falla
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return names
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			names[iface.Name] = true
+		}
+	}
+	return names
+}
+
+// setMemory rellena los campos de memoria en bytes y los de la unidad indicada.
+// Los bytes se reportan como enteros sin pérdida; el resto de unidades como decimales.
+func (m *SystemMetrics) setMemory(unit string, used, free uint64) {
+	m.MemoryUsedBytes, m.MemoryFreeBytes = &used, &free
+	switch unit {
+	case "kb":
+		m.MemoryUsedKB, m.MemoryFreeKB = scaled(used, unit), scaled(free, unit)
+	case "mb":
+		m.MemoryUsedMB, m.MemoryFreeMB = scaled(used, unit), scaled(free, unit)
+	case "gb":
+		m.MemoryUsedGB, m.MemoryFreeGB = scaled(used, unit), scaled(free, unit)
+	}
+}
+
+// setLoad rellena la carga media donde el sistema la ofrece. En Windows gopsutil solo la emula,
+// así que se omite; un error de lectura tampoco detiene la recolección.
+func (m *SystemMetrics) setLoad(ctx context.Context) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	avg, err := load.AvgWithContext(ctx)
+	if err != nil {
+		return
+	}
+	m.Load1, m.Load5, m.Load15 = &avg.Load1, &avg.Load5, &avg.Load15
+}
+
+// scaled convierte bytes a la unidad indicada
+func scaled(bytes uint64, unit string) *float64 {
+	v := float64(bytes) / memoryUnits[unit].divisor
+	return &v
+}
+
 // Name devuelve el nombre de este colector.
 // Implementa el método Name() de la interfaz Collector.
 func (c *SystemCollector) Name() string {
@@ -77,3 +321,46 @@ func (c *SystemCollector) Name() string {
 func (c *SystemCollector) GetInterval() time.Duration {
 	return c.interval
 }
+
+// Close libera los recursos del colector; SystemCollector no mantiene ninguno.
+// Implementa el método Close() de la interfaz Collector.
+func (c *SystemCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector.
+// Implementa la interfaz Describer.
+func (c *SystemCollector) Metadata() []MetricDescriptor {
+	descriptors := []MetricDescriptor{
+		{Name: "cpu_percent", Type: Gauge, Unit: UnitPercent, Description: "Uso total de CPU."},
+		{Name: "per_core", Type: Gauge, Unit: UnitPercent, Description: "Uso de CPU por núcleo."},
+		{Name: "load1", Type: Gauge, Unit: UnitNone, Description: "Carga media del último minuto (no disponible en Windows)."},
+		{Name: "load5", Type: Gauge, Unit: UnitNone, Description: "Carga media de los últimos 5 minutos."},
+		{Name: "load15", Type: Gauge, Unit: UnitNone, Description: "Carga media de los últimos 15 minutos."},
+		{Name: "memory_used_bytes", Type: Gauge, Unit: UnitBytes, Description: "Memoria utilizada."},
+		{Name: "memory_free_bytes", Type: Gauge, Unit: UnitBytes, Description: "Memoria libre."},
+		{Name: "memory_total_bytes", Type: Gauge, Unit: UnitBytes, Description: "Memoria total."},
+		{Name: "memory_available_bytes", Type: Gauge, Unit: UnitBytes, Description: "Memoria disponible sin recurrir a swap."},
+		{Name: "memory_used_percent", Type: Gauge, Unit: UnitPercent, Description: "Porcentaje de memoria utilizada."},
+		{Name: "bytes_sent", Type: Counter, Unit: UnitBytes, Description: "Bytes enviados por interfaz."},
+		{Name: "bytes_recv", Type: Counter, Unit: UnitBytes, Description: "Bytes recibidos por interfaz."},
+		{Name: "packets_sent", Type: Counter, Unit: UnitCount},
+		{Name: "packets_recv", Type: Counter, Unit: UnitCount},
+		{Name: "errors", Type: Counter, Unit: UnitCount, Description: "Errores de entrada y salida por interfaz."},
+		{Name: "bytes_sent_per_second", Type: Gauge, Unit: UnitPerSecond},
+		{Name: "bytes_recv_per_second", Type: Gauge, Unit: UnitPerSecond},
+		{Name: "packets_sent_per_second", Type: Gauge, Unit: UnitPerSecond},
+		{Name: "packets_recv_per_second", Type: Gauge, Unit: UnitPerSecond},
+		{Name: "total_bytes", Type: Gauge, Unit: UnitBytes, Description: "Espacio total por punto de montaje."},
+		{Name: "used_bytes", Type: Gauge, Unit: UnitBytes, Description: "Espacio utilizado por punto de montaje."},
+		{Name: "free_bytes", Type: Gauge, Unit: UnitBytes, Description: "Espacio libre por punto de montaje."},
+		{Name: "used_percent", Type: Gauge, Unit: UnitPercent, Description: "Porcentaje de espacio utilizado por punto de montaje."},
+	}
+	if unit := memoryUnits[c.memoryUnit]; c.memoryUnit != "bytes" {
+		descriptors = append(descriptors,
+			MetricDescriptor{Name: "memory_used_" + unit.suffix, Type: Gauge, Unit: unit.unit, Description: "Memoria utilizada."},
+			MetricDescriptor{Name: "memory_free_" + unit.suffix, Type: Gauge, Unit: unit.unit, Description: "Memoria libre."},
+		)
+	}
+	return descriptors
+}
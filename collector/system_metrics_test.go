@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/atrox39/logtick/collector/filter"
+	"github.com/atrox39/logtick/config"
+)
+
+func TestCollectDisksFiltersMounts(t *testing.T) {
+	partitions := []disk.PartitionStat{
+		{Mountpoint: "/", Fstype: "ext4"},
+		{Mountpoint: "/data/a", Fstype: "xfs"},
+		{Mountpoint: "/data/b", Fstype: "xfs"},
+		{Mountpoint: "/boot", Fstype: "vfat"},
+	}
+	usage := func(path string) (*disk.UsageStat, error) {
+		return &disk.UsageStat{Path: path, Total: 100, Used: 40, Free: 60, UsedPercent: 40}, nil
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"sin filtro", nil, []string{"/", "/boot", "/data/a", "/data/b"}},
+		{"glob", []string{"/data/*"}, []string{"/data/a", "/data/b"}},
+		{"regex", []string{"re:^/(boot)?$"}, []string{"/", "/boot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mounts, err := filter.Compile(tt.patterns)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			var got []string
+			for mount := range collectDisks(partitions, mounts, usage) {
+				got = append(got, mount)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("puntos de montaje = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectDisksSkipsUnreadableMounts(t *testing.T) {
+	partitions := []disk.PartitionStat{{Mountpoint: "/", Fstype: "ext4"}, {Mountpoint: "/secret", Fstype: "ext4"}}
+	usage := func(path string) (*disk.UsageStat, error) {
+		if path == "/secret" {
+			return nil, errors.New("permiso denegado")
+		}
+		return &disk.UsageStat{Total: 100, Used: 25, Free: 75, UsedPercent: 25}, nil
+	}
+
+	disks := collectDisks(partitions, nil, usage)
+	want := map[string]DiskUsage{"/": {Fstype: "ext4", TotalBytes: 100, UsedBytes: 25, FreeBytes: 75, UsedPercent: 25}}
+	if !reflect.DeepEqual(disks, want) {
+		t.Errorf("collectDisks = %+v, se esperaba %+v", disks, want)
+	}
+}
+
+func TestNewSystemCollectorRejectsInvalidMountPattern(t *testing.T) {
+	if _, err := NewSystemCollector(&config.Config{IntervalSeconds: 5, System: &config.SystemConfig{DiskMounts: []string{"re:("}}}); err == nil {
+		t.Error("NewSystemCollector aceptó un patrón inválido en disk_mounts")
+	}
+}
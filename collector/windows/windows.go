@@ -0,0 +1,65 @@
+package windows
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// ServiceStatus contiene el estado de un servicio de Windows
+type ServiceStatus struct {
+	State   string `json:"state"`   // running, stopped, paused, ... o not_found
+	Running bool   `json:"running"` // true solo si el servicio está en ejecución
+}
+
+// WindowsMetrics contiene el estado de los servicios configurados y los errores recientes del registro de eventos
+type WindowsMetrics struct {
+	Services       map[string]ServiceStatus `json:"services"`         // Mapa por nombre de servicio
+	EventLogErrors map[string]uint64        `json:"event_log_errors"` // Eventos de nivel Error/Crítico en el último intervalo, por registro
+}
+
+// WindowsCollector implementa la interfaz Collector para servicios y registro de eventos de Windows.
+// Fuera de Windows NewWindowsCollector siempre devuelve error.
+type WindowsCollector struct {
+	services  []string
+	eventLogs []string
+	interval  time.Duration
+	log       *logrus.Entry
+}
+
+// Registro del colector para que main lo construya cuando está habilitado
+func init() {
+	collector.Register(collector.Registration{
+		Name:    "windows",
+		Enabled: func(cfg *config.Config) bool { return cfg.Windows != nil && cfg.Windows.Enabled },
+		New: func(cfg *config.Config) (collector.Collector, error) {
+			return NewWindowsCollector(cfg.Windows)
+		},
+	})
+}
+
+// Name devuelve el nombre de este colector
+func (c *WindowsCollector) Name() string {
+	return "windows"
+}
+
+// GetInterval devuelve el intervalo de recolección para este colector
+func (c *WindowsCollector) GetInterval() time.Duration {
+	return c.interval
+}
+
+// Close libera los recursos del colector (no mantiene ninguno)
+func (c *WindowsCollector) Close() error {
+	return nil
+}
+
+// Metadata describe las métricas reportadas por este colector
+func (c *WindowsCollector) Metadata() []collector.MetricDescriptor {
+	return []collector.MetricDescriptor{
+		{Name: "running", Type: collector.Gauge, Unit: collector.UnitNone, Description: "1 si el servicio está en ejecución."},
+		{Name: "event_log_errors", Type: collector.Gauge, Unit: collector.UnitCount, Description: "Eventos de nivel Error o Crítico durante el último intervalo."},
+	}
+}
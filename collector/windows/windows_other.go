@@ -0,0 +1,21 @@
+//go:build !windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// NewWindowsCollector no está soportado fuera de Windows
+func NewWindowsCollector(cfg *config.WindowsConfig) (*WindowsCollector, error) {
+	return nil, fmt.Errorf("el colector de Windows solo está disponible en Windows")
+}
+
+// Collect no se invoca nunca fuera de Windows
+func (c *WindowsCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	return nil, fmt.Errorf("el colector de Windows solo está disponible en Windows")
+}
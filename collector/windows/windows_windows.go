@@ -0,0 +1,124 @@
+//go:build windows
+
+package windows
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+)
+
+// wevtutilTimeout limita la duración de cada consulta al registro de eventos
+const wevtutilTimeout = 30 * time.Second
+
+// stateNames traduce los estados del Service Control Manager
+var stateNames = map[svc.State]string{
+	svc.Stopped:         "stopped",
+	svc.StartPending:    "start_pending",
+	svc.StopPending:     "stop_pending",
+	svc.Running:         "running",
+	svc.ContinuePending: "continue_pending",
+	svc.PausePending:    "pause_pending",
+	svc.Paused:          "paused",
+}
+
+// NewWindowsCollector crea una nueva instancia de WindowsCollector.
+// Falla si no se puede abrir el Service Control Manager o si wevtutil no está disponible.
+func NewWindowsCollector(cfg *config.WindowsConfig) (*WindowsCollector, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("error al conectar con el Service Control Manager: %w", err)
+	}
+	m.Disconnect()
+
+	if len(cfg.EventLogs) > 0 {
+		if _, err := exec.LookPath("wevtutil"); err != nil {
+			return nil, fmt.Errorf("no se encontró wevtutil: %w", err)
+		}
+	}
+
+	return &WindowsCollector{
+		services:  cfg.Services,
+		eventLogs: cfg.EventLogs,
+		interval:  time.Duration(cfg.CollectionIntervalSeconds) * time.Second,
+		log:       logrus.WithField("collector", "windows"),
+	}, nil
+}
+
+// Collect recolecta el estado de los servicios y cuenta los errores recientes de cada registro
+func (c *WindowsCollector) Collect(ctx context.Context) (collector.MetricData, error) {
+	metrics := &WindowsMetrics{
+		Services:       make(map[string]ServiceStatus, len(c.services)),
+		EventLogErrors: make(map[string]uint64, len(c.eventLogs)),
+	}
+
+	if len(c.services) > 0 {
+		m, err := mgr.Connect()
+		if err != nil {
+			return nil, fmt.Errorf("error al conectar con el Service Control Manager: %w", err)
+		}
+		defer m.Disconnect()
+
+		for _, name := range c.services {
+			metrics.Services[name] = queryService(m, name)
+		}
+	}
+
+	// Un registro ilegible no invalida el resto de métricas
+	for _, logName := range c.eventLogs {
+		count, err := c.countErrors(ctx, logName)
+		if err != nil {
+			c.log.WithError(err).WithField("event_log", logName).Warn("Error al consultar el registro de eventos")
+			continue
+		}
+		metrics.EventLogErrors[logName] = count
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"services":   len(metrics.Services),
+		"event_logs": len(metrics.EventLogErrors),
+	}).Debug("Métricas de Windows recolectadas")
+
+	return metrics, nil
+}
+
+// queryService obtiene el estado de un servicio; los servicios inexistentes se reportan como not_found
+func queryService(m *mgr.Mgr, name string) ServiceStatus {
+	s, err := m.OpenService(name)
+	if err != nil {
+		return ServiceStatus{State: "not_found"}
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return ServiceStatus{State: "unknown"}
+	}
+	state, ok := stateNames[status.State]
+	if !ok {
+		state = "unknown"
+	}
+	return ServiceStatus{State: state, Running: status.State == svc.Running}
+}
+
+// countErrors cuenta los eventos de nivel Crítico (1) o Error (2) del último intervalo
+func (c *WindowsCollector) countErrors(ctx context.Context, logName string) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, wevtutilTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf("*[System[(Level=1 or Level=2) and TimeCreated[timediff(@SystemTime) <= %d]]]", c.interval.Milliseconds())
+	out, err := exec.CommandContext(ctx, "wevtutil", "qe", logName, "/q:"+query, "/f:xml").Output()
+	if err != nil {
+		return 0, fmt.Errorf("error al ejecutar wevtutil para '%s': %w", logName, err)
+	}
+	return uint64(bytes.Count(out, []byte("<Event "))), nil
+}
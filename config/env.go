@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ExpandEnv sustituye las referencias a variables de entorno de un valor: ${VAR}, $VAR y
+// ${VAR:-por defecto}, que usa el valor por defecto si la variable no está definida o está vacía.
+// "$$" produce un "$" literal y cualquier otro "$" se deja tal cual, de modo que valores como las
+// expresiones regulares ("^nginx$") no cambian. Devuelve un error si alguna variable referenciada
+// sin valor por defecto no está definida.
+func ExpandEnv(value string) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	var missing []string
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		next := value[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				b.WriteByte('$') // Sin cierre: literal
+				continue
+			}
+			expr := value[i+2 : i+2+end]
+			name, fallback, hasDefault := strings.Cut(expr, ":-")
+			if !isEnvName(name) {
+				b.WriteByte('$') // ${...} sin un nombre válido: literal
+				continue
+			}
+			if v, ok := os.LookupEnv(name); ok && (v != "" || !hasDefault) {
+				b.WriteString(v)
+			} else if hasDefault {
+				b.WriteString(fallback)
+			} else {
+				missing = append(missing, name)
+			}
+			i += 2 + end
+		case isEnvNameStart(next):
+			end := i + 2
+			for end < len(value) && isEnvNameChar(value[end]) {
+				end++
+			}
+			name := value[i+1 : end]
+			if v, ok := os.LookupEnv(name); ok {
+				b.WriteString(v)
+			} else {
+				missing = append(missing, name)
+			}
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("variables de entorno no definidas: %s", strings.Join(missing, ", "))
+	}
+	return b.String(), nil
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || c >= '0' && c <= '9'
+}
+
+func isEnvName(s string) bool {
+	if s == "" || !isEnvNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isEnvNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// expandConfigEnv expande las variables de entorno de todos los campos de texto de cfg, incluidos
+// los de listas y los valores de mapas. Los errores llevan la ruta YAML del campo.
+func expandConfigEnv(cfg *Config) []string {
+	var problems []string
+	walkEnv(reflect.ValueOf(cfg).Elem(), "", &problems)
+	return problems
+}
+
+func walkEnv(v reflect.Value, path string, problems *[]string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walkEnv(v.Elem(), path, problems)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if !field.IsExported() || name == "-" || field.Tag.Get("env") == "-" {
+				continue // env:"-" marca valores que usan $ con otro sentido (ej. log_format de Nginx)
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			walkEnv(v.Field(i), name, problems)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkEnv(v.Index(i), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			key, value := iter.Key(), iter.Value()
+			fieldPath := path + "." + key.String()
+			switch value.Kind() {
+			case reflect.String:
+				m := v
+				expandEnvField(value.String(), fieldPath, func(s string) {
+					m.SetMapIndex(key, reflect.ValueOf(s).Convert(value.Type()))
+				}, problems)
+			case reflect.Ptr:
+				walkEnv(value, fieldPath, problems)
+			}
+		}
+	case reflect.String:
+		expandEnvField(v.String(), path, v.SetString, problems)
+	}
+}
+
+// expandEnvField sustituye un valor si contiene referencias a variables de entorno
+func expandEnvField(value, path string, set func(string), problems *[]string) {
+	expanded, err := ExpandEnv(value)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		return
+	}
+	if expanded != value {
+		set(expanded)
+	}
+}
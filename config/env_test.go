@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig escribe content como config.yaml en un directorio temporal y devuelve su ruta
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("no se pudo escribir %s: %v", path, err)
+	}
+	return path
+}
+
+const envTestConfig = `agent_name: test
+agent_id: 6b1d0c9e-3f52-4b0e-9d7a-2f4c1e8a5b10
+interval_seconds: 5
+target_url: ${LOGTICK_TEST_URL%s}
+`
+
+func TestLoadConfigEnvExpansion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string // Lo que sigue al nombre de la variable dentro de ${...}
+		env     string // Valor de LOGTICK_TEST_URL; "" = no definida
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "variable no definida sin valor por defecto",
+			ref:     "",
+			wantErr: "LOGTICK_TEST_URL",
+		},
+		{
+			name: "variable no definida con valor por defecto",
+			ref:  ":-http://localhost:4003/metrics",
+			want: "http://localhost:4003/metrics",
+		},
+		{
+			name: "variable definida prevalece sobre el valor por defecto",
+			ref:  ":-http://localhost:4003/metrics",
+			env:  "https://ingest.example.com/metrics",
+			want: "https://ingest.example.com/metrics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("LOGTICK_TEST_URL", tt.env)
+			} else {
+				t.Setenv("LOGTICK_TEST_URL", "")
+				os.Unsetenv("LOGTICK_TEST_URL") // t.Setenv restaura el valor original al terminar
+			}
+			path := writeConfig(t, "config.yaml", strings.Replace(envTestConfig, "%s", tt.ref, 1))
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("se esperaba un error, se obtuvo target_url %v", cfg.TargetURL)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "no definida") {
+					t.Fatalf("el error no indica la variable que falta: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if len(cfg.TargetURL) != 1 || cfg.TargetURL[0] != tt.want {
+				t.Fatalf("target_url = %v, se esperaba %s", cfg.TargetURL, tt.want)
+			}
+		})
+	}
+}
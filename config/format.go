@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isJSONConfig indica si el archivo de configuración es JSON según su extensión; cualquier otra
+// (.yaml, .yml o ninguna) se trata como YAML
+func isJSONConfig(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".json")
+}
+
+// unmarshalConfig parsea data en cfg según el formato del archivo. El JSON se convierte a YAML antes
+// de decodificarlo, de modo que ambos formatos usan las mismas claves (las etiquetas yaml) y los
+// mismos decodificadores personalizados (ej. URLList).
+func unmarshalConfig(filePath string, data []byte, cfg *Config) error {
+	if !isJSONConfig(filePath) {
+		return yaml.Unmarshal(data, cfg)
+	}
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	converted, err := yaml.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(converted, cfg)
+}
+
+// parseConfigDocument devuelve el árbol de nodos del archivo tal como está escrito, sin valores por
+// defecto ni expansión de variables de entorno, para guardarlo cambiando solo lo que genera el agente
+func parseConfigDocument(filePath string, data []byte) (*yaml.Node, error) {
+	if isJSONConfig(filePath) {
+		var raw interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		converted, err := yaml.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// marshalConfig serializa el árbol de nodos de la configuración en el formato del archivo. En JSON
+// las claves conservan el orden del YAML y los comentarios se descartan.
+func marshalConfig(filePath string, doc *yaml.Node) ([]byte, error) {
+	if !isJSONConfig(filePath) {
+		return yaml.Marshal(doc)
+	}
+	var compact bytes.Buffer
+	if err := writeJSONNode(&compact, doc); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// writeJSONNode escribe un nodo YAML como JSON compacto, respetando el orden de las claves
+func writeJSONNode(b *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			b.WriteString("null")
+			return nil
+		}
+		return writeJSONNode(b, n.Content[0])
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(n.Content[i].Value)
+			b.Write(key)
+			b.WriteByte(':')
+			if err := writeJSONNode(b, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJSONNode(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case yaml.ScalarNode:
+		var value interface{}
+		if err := n.Decode(&value); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("valor %q no representable en JSON: %w", n.Value, err)
+		}
+		b.Write(encoded)
+	default:
+		return fmt.Errorf("nodo YAML no soportado en JSON (tipo %d)", n.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const formatTestYAML = `agent_name: test
+agent_id: 6b1d0c9e-3f52-4b0e-9d7a-2f4c1e8a5b10
+interval_seconds: 5
+target_url:
+  - http://primary:4003/metrics
+  - http://standby:4003/metrics
+labels:
+  env: prod
+mysql:
+  enabled: true
+  dsn: user:pass@tcp(127.0.0.1:3306)/mysql
+  collect_db_sizes: true
+nginx:
+  enabled: true
+  stub_status_url: http://localhost/nginx_status
+process:
+  enabled: true
+  process_names: [nginx, mysqld]
+log:
+  collector_levels:
+    mysql: debug
+`
+
+const formatTestJSON = `{
+  "agent_name": "test",
+  "agent_id": "6b1d0c9e-3f52-4b0e-9d7a-2f4c1e8a5b10",
+  "interval_seconds": 5,
+  "target_url": ["http://primary:4003/metrics", "http://standby:4003/metrics"],
+  "labels": {"env": "prod"},
+  "mysql": {
+    "enabled": true,
+    "dsn": "user:pass@tcp(127.0.0.1:3306)/mysql",
+    "collect_db_sizes": true
+  },
+  "nginx": {"enabled": true, "stub_status_url": "http://localhost/nginx_status"},
+  "process": {"enabled": true, "process_names": ["nginx", "mysqld"]},
+  "log": {"collector_levels": {"mysql": "debug"}}
+}
+`
+
+func TestJSONAndYAMLConfigsAreEquivalent(t *testing.T) {
+	// Ambos archivos en el mismo directorio: state_file se deriva de él
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	jsonPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(yamlPath, []byte(formatTestYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(formatTestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromYAML, err := LoadConfigWithOptions(yamlPath, LoadOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	fromJSON, err := LoadConfigWithOptions(jsonPath, LoadOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("la configuración JSON difiere de la YAML:\nYAML: %+v\nJSON: %+v", fromYAML, fromJSON)
+	}
+}
+
+func TestSaveConfigWritesJSON(t *testing.T) {
+	path := writeConfig(t, "config.json", formatTestJSON)
+	cfg, err := LoadConfigWithOptions(path, LoadOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("LoadConfigWithOptions: %v", err)
+	}
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("SaveConfig no escribió JSON válido: %v\n%s", err, data)
+	}
+	if raw["agent_id"] != cfg.AgentID {
+		t.Errorf("agent_id guardado = %v, se esperaba %s", raw["agent_id"], cfg.AgentID)
+	}
+
+	// El archivo guardado se vuelve a leer y, guardado otra vez, no cambia
+	reloaded, err := LoadConfigWithOptions(path, LoadOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("el JSON guardado no se puede recargar: %v", err)
+	}
+	if err := SaveConfig(reloaded, path); err != nil {
+		t.Fatalf("SaveConfig tras recargar: %v", err)
+	}
+	again, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("guardar de nuevo cambió el archivo:\nantes:\n%s\ndespués:\n%s", data, again)
+	}
+	if !reflect.DeepEqual(reloaded.TargetURL, cfg.TargetURL) || !reflect.DeepEqual(reloaded.MySQL, cfg.MySQL) {
+		t.Errorf("target_url o mysql cambiaron al guardar y recargar")
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Section devuelve la sección de configuración de un colector, identificada por su etiqueta YAML
+// (que coincide con el nombre del colector, ej. "mysql"), o nil si no existe o no está presente.
+func (c *Config) Section(collectorName string) interface{} {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag != collectorName || t.Field(i).Type.Kind() != reflect.Ptr {
+			continue
+		}
+		if f := v.Field(i); !f.IsNil() {
+			return f.Interface()
+		}
+		return nil
+	}
+	return nil
+}
+
+// CollectorChanged indica si la configuración de un colector difiere entre old y new
+func CollectorChanged(collectorName string, old, new *Config) bool {
+	return !reflect.DeepEqual(old.Section(collectorName), new.Section(collectorName))
+}
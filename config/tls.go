@@ -0,0 +1,48 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig define la configuración TLS de cliente de una conexión: la CA con la que verificar al
+// servidor y, para TLS mutuo, el certificado y la clave del agente
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file"`              // CA en PEM que firma el certificado del servidor (vacío = CAs del sistema)
+	CertFile           string `yaml:"cert_file"`            // Certificado de cliente en PEM; requiere key_file
+	KeyFile            string `yaml:"key_file"`             // Clave privada del certificado de cliente
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"` // No verificar el certificado del servidor (solo para pruebas)
+}
+
+// Build carga los archivos y devuelve la configuración para crypto/tls. field es el nombre del
+// bloque en el YAML (ej. "sender.tls") y se usa en los mensajes de error.
+func (t *TLSConfig) Build(field string) (*tls.Config, error) {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, fmt.Errorf("%s: cert_file y key_file deben indicarse juntos", field)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error al leer ca_file: %w", field, err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: ca_file no contiene certificados PEM válidos", field)
+		}
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error al cargar cert_file/key_file: %w", field, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ValidationError agrupa todos los problemas encontrados al validar la configuración,
+// para poder corregirlos de una vez en lugar de uno por arranque.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("configuración inválida (%d problemas):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// add registra un problema
+func (e *ValidationError) add(problem string) {
+	e.Problems = append(e.Problems, problem)
+}
+
+// addf registra un problema con formato
+func (e *ValidationError) addf(format string, args ...interface{}) {
+	e.add(fmt.Sprintf(format, args...))
+}
+
+// checkURL registra un problema si value no es una URL absoluta con uno de los esquemas permitidos.
+// Un valor vacío no se comprueba: los campos obligatorios se validan aparte.
+func (e *ValidationError) checkURL(field, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		e.addf("%s inválido '%s': %v", field, value, err)
+		return
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) && u.Host != "" {
+			return
+		}
+	}
+	e.addf("%s inválido '%s': se esperaba una URL %s con host", field, value, strings.Join(schemes, "/"))
+}
+
+// validateEndpoints comprueba el formato de las URLs y DSN de los destinos y de los colectores habilitados
+func (e *ValidationError) validateEndpoints(cfg *Config) {
+	for i, target := range cfg.TargetURL {
+		e.checkURL(fmt.Sprintf("target_url[%d]", i), target, "http", "https")
+	}
+	e.checkURL("websocket_log_url", cfg.WebSocketLogURL, "ws", "wss")
+	if cfg.Logs != nil {
+		e.checkURL("logs.websocket_url", cfg.Logs.WebSocketURL, "ws", "wss")
+	}
+	if cfg.Influx != nil && cfg.Influx.Enabled {
+		e.checkURL("influx.url", cfg.Influx.URL, "http", "https")
+	}
+	if cfg.OTLP != nil && cfg.OTLP.Enabled && cfg.OTLP.Protocol == "http" {
+		e.checkURL("otlp.endpoint", cfg.OTLP.Endpoint, "http", "https")
+	}
+	for _, sc := range cfg.Sinks {
+		e.checkURL(fmt.Sprintf("sinks '%s': url", sc.Name), sc.URL, "http", "https")
+	}
+
+	if cfg.MySQL != nil && cfg.MySQL.Enabled && cfg.MySQL.DSN != "" {
+		if _, err := mysql.ParseDSN(cfg.MySQL.DSN); err != nil {
+			e.addf("mysql.dsn inválido: %v", err)
+		}
+	}
+	if cfg.Nginx != nil && cfg.Nginx.Enabled {
+		e.checkURL("nginx.stub_status_url", cfg.Nginx.StubStatusURL, "http", "https")
+		e.checkURL("nginx.api_url", cfg.Nginx.APIURL, "http", "https")
+	}
+	if cfg.MongoDB != nil && cfg.MongoDB.Enabled {
+		e.checkURL("mongodb.uri", cfg.MongoDB.URI, "mongodb", "mongodb+srv")
+	}
+	if cfg.Elasticsearch != nil && cfg.Elasticsearch.Enabled {
+		e.checkURL("elasticsearch.url", cfg.Elasticsearch.URL, "http", "https")
+	}
+	if cfg.Jolokia != nil && cfg.Jolokia.Enabled {
+		e.checkURL("jolokia.url", cfg.Jolokia.URL, "http", "https")
+	}
+	if cfg.HAProxy != nil && cfg.HAProxy.Enabled {
+		e.checkURL("haproxy.stats_url", cfg.HAProxy.StatsURL, "http", "https")
+	}
+	if cfg.PromScrape != nil && cfg.PromScrape.Enabled {
+		e.checkURL("promscrape.url", cfg.PromScrape.URL, "http", "https")
+	}
+}
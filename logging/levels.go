@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSubsystem identifica las entradas que no pertenecen a ningún colector ni enviador
+const defaultSubsystem = "agent"
+
+// LevelFilter decide si una entrada se emite según el nivel de su subsistema.
+// El subsistema es el campo "collector" o "sender" de la entrada; si no tiene ninguno es "agent".
+type LevelFilter struct {
+	base      logrus.Level
+	overrides map[string]logrus.Level
+}
+
+// NewLevelFilter crea un filtro cuyo nivel por defecto es base
+func NewLevelFilter(base logrus.Level) *LevelFilter {
+	return &LevelFilter{base: base, overrides: make(map[string]logrus.Level)}
+}
+
+// Set fija el nivel mínimo de un subsistema (ej. "mysql", "websocket_logs")
+func (f *LevelFilter) Set(subsystem, level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("nivel de log inválido '%s' para '%s': %w", level, subsystem, err)
+	}
+	f.overrides[subsystem] = lvl
+	return nil
+}
+
+// MaxLevel devuelve el nivel más detallado entre el global y las excepciones.
+// El logger debe configurarse con este nivel para que las entradas lleguen al filtro.
+func (f *LevelFilter) MaxLevel() logrus.Level {
+	max := f.base
+	for _, lvl := range f.overrides {
+		if lvl > max {
+			max = lvl
+		}
+	}
+	return max
+}
+
+// Allows indica si la entrada supera el nivel mínimo de su subsistema
+func (f *LevelFilter) Allows(entry *logrus.Entry) bool {
+	lvl, ok := f.overrides[Subsystem(entry)]
+	if !ok {
+		lvl = f.base
+	}
+	return entry.Level <= lvl
+}
+
+// Subsystem devuelve el subsistema al que pertenece una entrada
+func Subsystem(entry *logrus.Entry) string {
+	if name, ok := entry.Data["collector"].(string); ok {
+		return name
+	}
+	if name, ok := entry.Data["sender"].(string); ok {
+		return name
+	}
+	return defaultSubsystem
+}
+
+// Formatter envuelve otro formateador y descarta las entradas que el filtro no permite
+type Formatter struct {
+	logrus.Formatter
+	Filter *LevelFilter
+}
+
+// Format formatea la entrada o devuelve nil si debe descartarse
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.Filter.Allows(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat es la marca de tiempo (UTC) que se añade al nombre de los archivos rotados:
+// agent.log pasa a agent-2026-01-02T15-04-05.000.log
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile escribe los logs en un archivo y lo rota cuando superaría maxSize: el archivo actual
+// se renombra con la fecha de rotación y se empieza uno nuevo. Tras cada rotación se borran los
+// archivos rotados que exceden maxBackups o son más antiguos que maxAge.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int           // 0 = se conservan todos
+	maxAge     time.Duration // 0 = sin límite de antigüedad
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingFile abre (o crea) path para añadir líneas al final
+func NewRotatingFile(path string, maxSizeMB, maxBackups, maxAgeDays int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+		maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open abre el archivo de log y toma su tamaño actual
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de log %s: %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error al leer el archivo de log %s: %w", f.path, err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write añade p al archivo, rotándolo antes si con p superaría el tamaño máximo.
+// Una sola escritura mayor que el máximo va entera a un archivo nuevo.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		// Si no se pudo rotar pero el archivo sigue abierto, la línea no se pierde: se escribe en el
+		// actual y la rotación se reintenta en la siguiente escritura
+		if err := f.rotate(); err != nil && f.file == nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renombra el archivo actual, abre uno nuevo y borra los rotados que sobran
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error al cerrar el archivo de log %s: %w", f.path, err)
+	}
+	f.file = nil
+	ext := filepath.Ext(f.path)
+	backup := strings.TrimSuffix(f.path, ext) + "-" + time.Now().UTC().Format(backupTimeFormat) + ext
+	if err := os.Rename(f.path, backup); err != nil {
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("error al rotar el archivo de log %s: %w", f.path, err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// prune borra los archivos rotados que exceden maxBackups o maxAge. Los errores se ignoran: un
+// archivo que no se pudo borrar se vuelve a intentar en la siguiente rotación.
+func (f *RotatingFile) prune() {
+	if f.maxBackups == 0 && f.maxAge == 0 {
+		return
+	}
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return
+	}
+	type backup struct {
+		name string
+		at   time.Time
+	}
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		at, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue // Otro archivo con el mismo prefijo
+		}
+		backups = append(backups, backup{name: name, at: at})
+	}
+	// Del más reciente al más antiguo
+	sort.Slice(backups, func(i, j int) bool { return backups[i].at.After(backups[j].at) })
+
+	cutoff := time.Now().Add(-f.maxAge)
+	for i, b := range backups {
+		if (f.maxBackups > 0 && i >= f.maxBackups) || (f.maxAge > 0 && b.at.Before(cutoff)) {
+			os.Remove(filepath.Join(filepath.Dir(f.path), b.name))
+		}
+	}
+}
+
+// Close sincroniza el archivo con el disco y lo cierra. Las escrituras posteriores fallan.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	syncErr := f.file.Sync()
+	err := f.file.Close()
+	f.file = nil
+	if syncErr != nil {
+		return syncErr
+	}
+	return err
+}
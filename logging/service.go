@@ -0,0 +1,31 @@
+package logging
+
+import "sync"
+
+// ServiceLogFunc entrega una línea de log de un servicio monitoreado al destino de logs.
+// Coincide con sender.WebSocketLogSender.SendLog.
+type ServiceLogFunc func(service, message, level string)
+
+var (
+	serviceLogMu sync.RWMutex
+	serviceLog   ServiceLogFunc
+)
+
+// SetServiceLog fija el destino de ServiceLog; nil lo desactiva
+func SetServiceLog(f ServiceLogFunc) {
+	serviceLogMu.Lock()
+	defer serviceLogMu.Unlock()
+	serviceLog = f
+}
+
+// ServiceLog envía una línea de log de un servicio monitoreado (ej. un error devuelto por MySQL)
+// tal cual, sin pasar por el logger del agente ni por sus niveles. Si el envío de logs está
+// desactivado, la línea se descarta.
+func ServiceLog(service, message, level string) {
+	serviceLogMu.RLock()
+	f := serviceLog
+	serviceLogMu.RUnlock()
+	if f != nil {
+		f(service, message, level)
+	}
+}
@@ -0,0 +1,239 @@
+package logtail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/config"
+)
+
+// Ritmo de sondeo de los archivos y longitud máxima de una línea; una línea más larga se envía
+// partida para que un archivo sin saltos de línea no haga crecer el búfer sin límite
+const (
+	pollInterval  = time.Second
+	maxLineLength = 64 * 1024
+)
+
+// SendFunc recibe cada línea leída; coincide con logging.ServiceLog
+type SendFunc func(service, message, level string)
+
+// Tailer sigue un archivo de log como tail -F: empieza por el final, envía cada línea nueva y
+// detecta la rotación (el archivo se sustituye por otro) y el truncado, continuando desde el
+// principio del archivo nuevo.
+type Tailer struct {
+	path    string
+	service string
+	level   string // Nivel fijo; vacío para deducirlo de cada línea
+	send    SendFunc
+	log     *logrus.Entry
+
+	file    *os.File
+	info    os.FileInfo // Identidad del archivo abierto, para detectar la rotación
+	offset  int64
+	pending []byte // Línea incompleta pendiente del siguiente salto de línea
+}
+
+// NewTailer crea un Tailer para el archivo configurado. El archivo no tiene que existir todavía.
+func NewTailer(cfg config.LogFileConfig, send SendFunc) *Tailer {
+	return &Tailer{
+		path:    cfg.Path,
+		service: cfg.Service,
+		level:   cfg.Level,
+		send:    send,
+		log:     logrus.WithFields(logrus.Fields{"sender": "logtail", "file": cfg.Path}),
+	}
+}
+
+// Run sigue el archivo hasta que se cancele ctx. Es bloqueante.
+func (t *Tailer) Run(ctx context.Context) {
+	defer t.close()
+
+	t.open(true)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.poll()
+		}
+	}
+}
+
+// open abre el archivo; con atEnd empieza tras el contenido existente, como tail -f
+func (t *Tailer) open(atEnd bool) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) || atEnd {
+			t.log.WithError(err).Warn("No se pudo abrir el archivo de log. Se reintentará.")
+		}
+		return
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		t.log.WithError(err).Warn("No se pudo consultar el archivo de log. Se reintentará.")
+		return
+	}
+
+	t.file = f
+	t.info = info
+	t.offset = 0
+	t.pending = nil
+	if atEnd {
+		t.offset = info.Size()
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		t.log.WithError(err).Warn("No se pudo posicionar en el archivo de log.")
+	}
+}
+
+// close cierra el archivo abierto, si lo hay
+func (t *Tailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// poll lee las líneas nuevas y comprueba si el archivo se rotó o truncó
+func (t *Tailer) poll() {
+	if t.file == nil {
+		t.open(false)
+		if t.file == nil {
+			return
+		}
+	}
+	t.read()
+
+	current, err := os.Stat(t.path)
+	switch {
+	case err != nil:
+		// Rotado y aún sin sustituir: se sigue leyendo el archivo abierto hasta que aparezca el nuevo
+	case !os.SameFile(t.info, current):
+		t.read() // Lo escrito en el archivo antiguo justo antes de rotar
+		t.flushPending()
+		t.close()
+		t.log.Info("Archivo de log rotado. Se continúa con el archivo nuevo.")
+		t.open(false)
+		if t.file != nil {
+			t.read()
+		}
+	case current.Size() < t.offset:
+		t.log.Info("Archivo de log truncado. Se continúa desde el principio.")
+		t.pending = nil
+		t.offset = 0
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			t.log.WithError(err).Warn("No se pudo posicionar en el archivo de log.")
+			t.close()
+			return
+		}
+		t.read()
+	}
+}
+
+// read envía las líneas completas añadidas desde la última lectura
+func (t *Tailer) read() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.file.Read(buf)
+		if n > 0 {
+			t.offset += int64(n)
+			t.consume(buf[:n])
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.log.WithError(err).Warn("Error al leer el archivo de log.")
+			}
+			return
+		}
+	}
+}
+
+// consume separa data en líneas y envía las completas, guardando el resto en pending
+func (t *Tailer) consume(data []byte) {
+	t.pending = append(t.pending, data...)
+	for {
+		i := bytes.IndexByte(t.pending, '\n')
+		if i < 0 {
+			break
+		}
+		t.emit(t.pending[:i])
+		t.pending = t.pending[i+1:]
+	}
+	for len(t.pending) >= maxLineLength {
+		t.emit(t.pending[:maxLineLength])
+		t.pending = t.pending[maxLineLength:]
+	}
+	t.pending = append([]byte(nil), t.pending...) // No retener el búfer completo
+}
+
+// flushPending envía la última línea sin salto de línea de un archivo que se deja de leer
+func (t *Tailer) flushPending() {
+	if len(t.pending) > 0 {
+		t.emit(t.pending)
+		t.pending = nil
+	}
+}
+
+// emit envía una línea no vacía con el nivel fijo o el deducido de su contenido
+func (t *Tailer) emit(line []byte) {
+	message := strings.TrimRight(string(line), "\r")
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+	level := t.level
+	if level == "" {
+		level = InferLevel(message)
+	}
+	t.send(t.service, message, level)
+}
+
+// levelKeywords asocia las palabras habituales en los logs con los niveles de logrus,
+// de más a menos grave para que gane el primero que aparezca
+var levelKeywords = []struct {
+	level string
+	words []string
+}{
+	{"fatal", []string{"fatal", "panic", "crit", "critical", "emerg", "emergency", "alert"}},
+	{"error", []string{"error", "err", "severe"}},
+	{"warning", []string{"warn", "warning"}},
+	{"debug", []string{"debug"}},
+	{"trace", []string{"trace"}},
+}
+
+// levelSearchWords limita la búsqueda al principio de la línea, donde los formatos habituales
+// escriben el nivel, para que un "error" en el texto del mensaje no cambie el nivel
+const levelSearchWords = 8
+
+// InferLevel deduce el nivel de una línea buscando palabras como ERROR, [warn] o level=debug
+// entre sus primeras palabras. Sin coincidencias devuelve "info".
+func InferLevel(line string) string {
+	words := strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	})
+	if len(words) > levelSearchWords {
+		words = words[:levelSearchWords]
+	}
+	found := make(map[string]bool, len(words))
+	for _, w := range words {
+		found[w] = true
+	}
+	for _, k := range levelKeywords {
+		for _, w := range k.words {
+			if found[w] {
+				return k.level
+			}
+		}
+	}
+	return "info"
+}
@@ -2,31 +2,63 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/collector/httpclient"
 	"github.com/atrox39/logtick/collector/mysql"
-	"github.com/atrox39/logtick/collector/nginx"
-	"github.com/atrox39/logtick/collector/process"
 	"github.com/atrox39/logtick/config"
+	"github.com/atrox39/logtick/logging"
+	"github.com/atrox39/logtick/logtail"
+	"github.com/atrox39/logtick/promexport"
+	"github.com/atrox39/logtick/report"
 	"github.com/atrox39/logtick/sender"
+	"github.com/atrox39/logtick/state"
 	"github.com/atrox39/logtick/utils"
+	"github.com/atrox39/logtick/version"
+	"github.com/atrox39/logtick/web"
 
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+
+	// Los colectores se registran en init(); basta con importarlos
+	_ "github.com/atrox39/logtick/collector/conntrack"
+	_ "github.com/atrox39/logtick/collector/cri"
+	_ "github.com/atrox39/logtick/collector/diskio"
+	_ "github.com/atrox39/logtick/collector/dns"
+	_ "github.com/atrox39/logtick/collector/elasticsearch"
+	_ "github.com/atrox39/logtick/collector/haproxy"
+	_ "github.com/atrox39/logtick/collector/jolokia"
+	_ "github.com/atrox39/logtick/collector/mongodb"
+	_ "github.com/atrox39/logtick/collector/nginx"
+	_ "github.com/atrox39/logtick/collector/ports"
+	_ "github.com/atrox39/logtick/collector/postgres"
+	_ "github.com/atrox39/logtick/collector/process"
+	_ "github.com/atrox39/logtick/collector/promscrape"
+	_ "github.com/atrox39/logtick/collector/sensors"
+	_ "github.com/atrox39/logtick/collector/smart"
+	_ "github.com/atrox39/logtick/collector/windows"
 )
 
-const configFilePath = "config.yaml"
-const metricsPort = ":9090" // Puerto para el endpoint de métricas de Prometheus y la UI
+// httpShutdownTimeout limita la espera a que terminen las peticiones en curso al apagar el servidor de métricas
+const httpShutdownTimeout = 5 * time.Second
+
+// finalFlushTimeout limita el envío del último reporte al apagar, cuando mainCtx ya está cancelado
+const finalFlushTimeout = 5 * time.Second
 
 // Definir métricas de Prometheus para el propio agente
 var (
@@ -60,6 +92,43 @@ var (
 		},
 		[]string{"type", "agent_name", "agent_id"},
 	)
+	// Estado detallado del ciclo de vida de cada colector (1 en el estado actual, 0 en el resto)
+	collectorState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "agent_collector_state",
+			Help: "Lifecycle state of each collector: disabled, pending, init_failed, starting, up or failing (1 = current state).",
+		},
+		[]string{"type", "agent_name", "agent_id", "state"},
+	)
+	// Tamaño serializado de la sección de cada colector dentro del reporte
+	collectorPayloadBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "agent_collector_payload_bytes",
+			Help: "Serialized JSON size in bytes contributed by each collector to the report.",
+		},
+		[]string{"collector"},
+	)
+	buildInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "agent_build_info",
+			Help: "Build information of the running agent. Always 1.",
+		},
+		[]string{"version", "commit", "date", "go_version"},
+	)
+	reportSerializationErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "agent_report_serialization_errors_total",
+			Help: "Total failures serializing collected data or reports. collector is \"report\" when the assembled report failed.",
+		},
+		[]string{"collector"},
+	)
+	nonFiniteValues = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "agent_nonfinite_values_total",
+			Help: "Total NaN/Inf float values replaced or omitted before serialization, per collector.",
+		},
+		[]string{"collector"},
+	)
 )
 
 func init() {
@@ -68,29 +137,28 @@ func init() {
 	prometheus.MustRegister(metricsSent)
 	prometheus.MustRegister(collectionDuration)
 	prometheus.MustRegister(collectorStatus)
-}
-
-// AgentReport encapsula todas las métricas recolectadas para un envío consolidado
-type AgentReport struct {
-	AgentID   string                   `json:"agent_id"`
-	AgentName string                   `json:"agent_name"`
-	Timestamp int64                    `json:"timestamp"`
-	System    *collector.SystemMetrics `json:"system_metrics,omitempty"`
-	MySQL     *mysql.MySQLMetrics      `json:"mysql_metrics,omitempty"`
-	Nginx     *nginx.NginxMetrics      `json:"nginx_metrics,omitempty"`
-	Process   *process.ProcessMetrics  `json:"process_metrics,omitempty"`
-	// Añadir más tipos de métricas aquí según se implementen los colectores
+	prometheus.MustRegister(collectorState)
+	prometheus.MustRegister(collectorPayloadBytes)
+	prometheus.MustRegister(nonFiniteValues)
+	prometheus.MustRegister(reportSerializationErrors)
+	prometheus.MustRegister(buildInfo)
+	prometheus.MustRegister(sender.InFlightSends)
+	prometheus.MustRegister(sender.BudgetUtilization)
+	prometheus.MustRegister(sender.BufferedReports)
+	prometheus.MustRegister(sender.SpooledReports)
 }
 
 type WebSocketLogHook struct {
 	sender *sender.WebSocketLogSender
 	levels []logrus.Level
+	filter *logging.LevelFilter // Niveles por subsistema; los hooks no pasan por el formateador
 }
 
-func NewWebSocketLogHook(s *sender.WebSocketLogSender, levels []logrus.Level) *WebSocketLogHook {
+func NewWebSocketLogHook(s *sender.WebSocketLogSender, levels []logrus.Level, filter *logging.LevelFilter) *WebSocketLogHook {
 	return &WebSocketLogHook{
 		sender: s,
 		levels: levels,
+		filter: filter,
 	}
 }
 
@@ -99,6 +167,11 @@ func (h *WebSocketLogHook) Levels() []logrus.Level {
 }
 
 func (h *WebSocketLogHook) Fire(entry *logrus.Entry) error {
+	// Los logs del propio sender se escriben con su mutex tomado; reenviarlos lo bloquearía
+	if sender.IsOwnEntry(entry) || !h.filter.Allows(entry) {
+		return nil
+	}
+
 	service := "agent"
 	if svc, ok := entry.Data["collector"].(string); ok {
 		service = svc
@@ -109,33 +182,99 @@ func (h *WebSocketLogHook) Fire(entry *logrus.Entry) error {
 }
 
 // Variable global para almacenar las últimas métricas para la UI interna
-var latestAgentReport *AgentReport
-var mu sync.RWMutex // Mutex para proteger latestAgentReport
+var latestAgentReport *report.AgentReport
+var latestReports *reportHistory // Reportes recientes para las gráficas de la UI (/api/metrics/history)
+var mu sync.RWMutex              // Mutex para proteger latestAgentReport y latestReports
+
+// reportHistory es un búfer circular con los últimos reportes de la UI: al llegar uno nuevo con el
+// búfer lleno se descarta el más antiguo. No es seguro para uso concurrente (lo protege mu).
+type reportHistory struct {
+	entries []*report.AgentReport
+	next    int // Posición donde se escribe el siguiente reporte
+	full    bool
+}
+
+func newReportHistory(size int) *reportHistory {
+	return &reportHistory{entries: make([]*report.AgentReport, size)}
+}
+
+// add guarda r, reemplazando al reporte más antiguo si el búfer está lleno
+func (h *reportHistory) add(r *report.AgentReport) {
+	h.entries[h.next] = r
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list devuelve los reportes guardados del más antiguo al más reciente
+func (h *reportHistory) list() []*report.AgentReport {
+	if !h.full {
+		return append(make([]*report.AgentReport, 0, h.next), h.entries[:h.next]...)
+	}
+	return append(append(make([]*report.AgentReport, 0, len(h.entries)), h.entries[h.next:]...), h.entries[:h.next]...)
+}
+
+// AgentStats contiene estadísticas internas del agente expuestas en /api/stats
+type AgentStats struct {
+	PayloadBytes map[string]int `json:"collector_payload_bytes"` // Bytes aportados por cada colector al reporte
+}
+
+var agentStats = &AgentStats{PayloadBytes: make(map[string]int)}
+var statsMu sync.RWMutex // Mutex para proteger agentStats
+
+// Metadatos (unidad y tipo) de las métricas de cada colector activo, servidos en /api/metadata
+var collectorMetadata = make(map[string][]collector.MetricDescriptor)
+var metadataMu sync.RWMutex // Mutex para proteger collectorMetadata
 
 func main() {
-	initAgent := flag.Bool("init", false, "Genera un archivo config.yaml inicial si no existe y sale.")
+	configFile := flag.String("config", "config.yaml", "Archivo de configuración: YAML, o JSON si la extensión es .json.")
+	initAgent := flag.Bool("init", false, "Genera el archivo de configuración inicial (-config) si no existe y sale.")
 	server := flag.Bool("server", false, "Inicia el servidor de pruebas para recibir métricas.")
+	serverAddr := flag.String("server-addr", ":4003", "Dirección de escucha del servidor de pruebas (con -server).")
+	serverCert := flag.String("server-cert", "", "Certificado para que el servidor de pruebas sirva HTTPS (con -server y -server-key).")
+	serverKey := flag.String("server-key", "", "Clave privada del certificado de -server-cert.")
+	validate := flag.Bool("validate", false, "Valida el archivo de configuración, muestra todos los problemas encontrados y sale (código 1 si no es válido).")
+	dryRun := flag.Bool("dry-run", false, "Recolecta y muestra por stdout el JSON que se enviaría, sin enviar nada.")
+	webDir := flag.String("web-dir", "", "Sirve la UI desde este directorio en lugar de la incluida en el binario (para desarrollo); sustituye a web_dir.")
+	showVersion := flag.Bool("version", false, "Muestra la versión, el commit y la fecha de compilación y sale.")
 	flag.Parse()
 
+	if *showVersion {
+		build := version.Get()
+		fmt.Printf("logtick %s (commit %s, compilado %s, %s)\n", build.Version, build.Commit, build.Date, build.GoVersion)
+		os.Exit(0)
+	}
+
 	if *initAgent {
-		fmt.Printf("Intentando generar un archivo de configuración en: %s\n", configFilePath)
-		_, err := config.LoadConfig(configFilePath)
+		fmt.Printf("Intentando generar un archivo de configuración en: %s\n", *configFile)
+		_, err := config.LoadConfig(*configFile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error al inicializar la configuración: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Configuración inicial generada/verificada. Puedes modificarla en 'config.yaml'.")
+		fmt.Printf("Configuración inicial generada/verificada. Puedes modificarla en '%s'.\n", *configFile)
+		os.Exit(0)
+	}
+
+	if *validate {
+		if _, err := config.LoadConfigWithOptions(*configFile, config.LoadOptions{ReadOnly: true}); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Configuración %s válida.\n", *configFile)
 		os.Exit(0)
 	}
 
 	if *server {
-		utils.Server()
+		utils.Server(utils.ServerOptions{Addr: *serverAddr, CertFile: *serverCert, KeyFile: *serverKey})
 		os.Exit(0)
 		return
 	}
 
 	// 1. Cargar configuración y configurar Logrus
-	cfg, err := config.LoadConfig(configFilePath)
+	// En dry-run no se envía nada, así que target_url no es obligatorio
+	cfg, err := config.LoadConfigWithOptions(*configFile, config.LoadOptions{AllowMissingTarget: *dryRun})
 	if err != nil {
 		logrus.Fatalf("Error al cargar la configuración: %v", err)
 	}
@@ -145,205 +284,973 @@ func main() {
 		logrus.Errorf("Nivel de log inválido '%s', usando info por defecto.", cfg.LogLevel)
 		logLevel = logrus.InfoLevel
 	}
-	logrus.SetLevel(logLevel)
-	logrus.SetFormatter(&logrus.JSONFormatter{})
-	logrus.SetOutput(os.Stdout)
+	// Niveles por subsistema: el logger usa el más detallado y el filtro descarta el resto
+	levelFilter := logging.NewLevelFilter(logLevel)
+	for subsystem, level := range cfg.LogLevels {
+		if err := levelFilter.Set(subsystem, level); err != nil {
+			logrus.WithError(err).Error("Se ignora el nivel de log del subsistema.")
+		}
+	}
+	logrus.SetLevel(levelFilter.MaxLevel())
+	// Sin log.file los logs van a stdout. El archivo se cierra al final del apagado para que las
+	// últimas líneas lleguen al disco.
+	var logFile *logging.RotatingFile
+	if cfg.Log != nil && cfg.Log.File != "" {
+		logFile, err = logging.NewRotatingFile(cfg.Log.File, cfg.Log.MaxSizeMB, cfg.Log.MaxBackups, cfg.Log.MaxAgeDays)
+		if err != nil {
+			logrus.Fatalf("Error al abrir log.file: %v", err)
+		}
+		logrus.SetOutput(logFile)
+	} else {
+		logrus.SetOutput(os.Stdout)
+	}
+	logrus.SetFormatter(&logging.Formatter{Formatter: newLogFormatter(cfg.Log), Filter: levelFilter})
+
+	build := version.Get()
+	buildInfo.WithLabelValues(build.Version, build.Commit, build.Date, build.GoVersion).Set(1)
 
 	logrus.WithFields(logrus.Fields{
+		"agent_version":     build.Version,
 		"agent_name":        cfg.AgentName,
 		"agent_id":          cfg.AgentID,
 		"global_interval_s": cfg.IntervalSeconds,
 		"target_url":        cfg.TargetURL,
+		"sender_auth":       cfg.Sender.Auth.Describe(),
 		"log_level":         cfg.LogLevel,
 	}).Info("Configuración cargada y logger inicializado.")
 
+	latestReports = newReportHistory(cfg.HistorySize)
+
 	// 3. Configurar contexto para el apagado elegante (ANTES DE INICIALIZAR SENDERS/COLLECTORS)
 	// PASO CRÍTICO: No uses defer cancel() aquí. La cancelación se maneja por la señal.
 	mainCtx, mainCancel := context.WithCancel(context.Background())
 	defer mainCancel() // Asegúrate que mainCancel() se llame al final del main para limpiar goroutines
 
+	// Servidor de métricas y UI; se detiene de forma ordenada al recibir la señal de terminación
+	srv := &http.Server{Addr: cfg.MetricsListenAddress}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	// SIGHUP se captura desde ya: sin esto, uno recibido durante el arranque (ej. en el período de gracia
+	// de MySQL) terminaría el proceso. Se atiende cuando los colectores ya arrancaron.
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
 	go func() {
 		sig := <-sigCh
 		logrus.WithField("signal", sig).Info("Señal de terminación recibida. Iniciando apagado...")
 		mainCancel() // Call mainCancel() here when a signal is received
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logrus.WithError(err).Warn("El servidor de métricas y UI no se detuvo a tiempo.")
+		}
 	}()
 
 	// 2. Inicializar los enviadores
-	httpSender := sender.NewHTTPSender(cfg.TargetURL)
+	// main solo conoce la interfaz Sink; el destino concreto se decide aquí
+	// Los valores recolectados se exponen siempre en /metrics a través del puente, además del envío;
+	// en modo prometheus_only no hay envío y el puente es la única salida
+	constLabels := prometheus.Labels{"agent_name": cfg.AgentName, "agent_id": cfg.AgentID}
+	for name, value := range cfg.Labels {
+		constLabels[name] = value
+	}
+	bridge := promexport.NewBridge(constLabels)
+	prometheus.MustRegister(bridge)
 
-	// Pasa el contexto principal al WebSocketLogSender para que sepa cuándo detener su bucle de reconexión
-	wsLogSender := sender.NewWebSocketLogSender(mainCtx, cfg.WebSocketLogURL, cfg.AgentID, cfg.AgentName)
-	// No necesitas un defer wsLogSender.Close() aquí si wsLogSender.Close() ya es llamado por mainCancel a través del contexto
+	var sink sender.Sink
+	if *dryRun {
+		sink = sender.NewStdoutSink(os.Stdout)
+		logrus.Info("Modo dry-run: los reportes se muestran por stdout y no se envían.")
+	} else if cfg.PrometheusOnly {
+		logrus.Info("Modo prometheus_only: el envío al backend está deshabilitado.")
+	} else {
+		// Todos los intentos de envío comparten un mismo presupuesto para acotar la tasa de salida
+		budget := sender.NewBudget(cfg.Sender.BudgetPerSecond, cfg.Sender.BudgetBurst)
+		backoff := time.Duration(cfg.Sender.RetryBackoffMs) * time.Millisecond
+		withRetries := func(s sender.Sink) sender.Sink {
+			return sender.NewRetrySink(s, budget, cfg.Sender.MaxRetries, backoff, cfg.Sender.BufferSize)
+		}
+
+		// Destino por defecto: recibe las secciones de los colectores sin ruta propia
+		switch {
+		case cfg.OutputFormat == "graphite":
+			sink, err = sender.NewGraphiteSender(cfg.Graphite)
+		case len(cfg.TargetURL) > 0:
+			var encoder sender.Encoder
+			if encoder, err = sender.NewEncoder(cfg.OutputFormat); err == nil {
+				sink, err = sender.NewHTTPSender(cfg.TargetURL, cfg.Sender, encoder)
+			}
+		}
+		if err != nil {
+			logrus.WithError(err).Fatalf("Error al inicializar el enviador (%s).", cfg.OutputFormat)
+		}
+		if sink != nil && cfg.Sender.SpoolDir != "" {
+			// El spool en disco sustituye al búfer en memoria: los reportes fallidos sobreviven a un reinicio
+			retry := sender.NewRetrySink(sink, budget, cfg.Sender.MaxRetries, backoff, 0)
+			sink, err = sender.NewSpoolSink(mainCtx, retry, cfg.Sender.SpoolDir, cfg.Sender.SpoolMaxBytes)
+			if err != nil {
+				logrus.WithError(err).Fatal("Error al inicializar el spool de envío.")
+			}
+			logrus.WithFields(logrus.Fields{"dir": cfg.Sender.SpoolDir, "max_bytes": cfg.Sender.SpoolMaxBytes}).Info("Spool en disco para envíos fallidos habilitado.")
+		} else if sink != nil {
+			sink = withRetries(sink)
+		}
+
+		// InfluxDB se suma al destino por defecto (o lo sustituye si no hay otro), con su propio búfer
+		if cfg.Influx != nil && cfg.Influx.Enabled {
+			influx, err := sender.NewInfluxSender(cfg.Influx)
+			if err != nil {
+				logrus.WithError(err).Fatal("Error al inicializar el enviador de InfluxDB.")
+			}
+			if sink == nil {
+				sink = withRetries(influx)
+			} else {
+				sink = sender.MultiSink{sink, withRetries(influx)}
+			}
+			logrus.WithFields(logrus.Fields{"url": cfg.Influx.URL, "org": cfg.Influx.Org, "bucket": cfg.Influx.Bucket}).Info("Escritura en InfluxDB habilitada.")
+		}
+
+		// OTLP, igual que InfluxDB: los metadatos de los colectores deciden entre gauges y sumas
+		if cfg.OTLP != nil && cfg.OTLP.Enabled {
+			otlp, err := sender.NewOTLPSender(cfg.OTLP, func(name string) []collector.MetricDescriptor {
+				metadataMu.RLock()
+				defer metadataMu.RUnlock()
+				return collectorMetadata[name]
+			})
+			if err != nil {
+				logrus.WithError(err).Fatal("Error al inicializar el exportador OTLP.")
+			}
+			if sink == nil {
+				sink = withRetries(otlp)
+			} else {
+				sink = sender.MultiSink{sink, withRetries(otlp)}
+			}
+			logrus.WithFields(logrus.Fields{"endpoint": cfg.OTLP.Endpoint, "protocol": cfg.OTLP.Protocol}).Info("Exportación OTLP habilitada.")
+		}
+
+		// Rutas por colector (sinks): cada destino recibe solo las secciones de sus colectores
+		if len(cfg.Sinks) > 0 {
+			routes := make([]sender.Route, 0, len(cfg.Sinks))
+			for i := range cfg.Sinks {
+				sc := &cfg.Sinks[i]
+				routeSink, err := sender.NewSinkFromConfig(sc, cfg.Sender)
+				if err != nil {
+					logrus.WithError(err).Fatalf("Error al inicializar el destino '%s'.", sc.Name)
+				}
+				routes = append(routes, sender.Route{Name: sc.Name, Collectors: sc.Collectors, Sink: withRetries(routeSink)})
+				logrus.WithFields(logrus.Fields{"sink": sc.Name, "format": sc.Format, "collectors": sc.Collectors}).Info("Destino enrutado configurado.")
+			}
+			sink = sender.NewRouter(routes, sink)
+		}
+	}
 
-	logrus.AddHook(NewWebSocketLogHook(wsLogSender, logrus.AllLevels))
+	// El WebSocketLogSender no usa el contexto principal: se cierra al final del apagado para que
+	// los logs del apagado y el resumen de repeticiones pendiente también se envíen
+	var wsLogSender *sender.WebSocketLogSender
+	if cfg.LogsEnabled() {
+		wsOpts := sender.WebSocketLogOptions{
+			PingInterval: time.Duration(cfg.Logs.PingIntervalSeconds) * time.Second,
+			PongTimeout:  time.Duration(cfg.Logs.PongTimeoutSeconds) * time.Second,
+			WriteTimeout: time.Duration(cfg.Logs.WriteTimeoutSeconds) * time.Second,
+		}
+		if cfg.LogDedup != nil && cfg.LogDedup.Enabled {
+			wsOpts.DedupWindow = time.Duration(cfg.LogDedup.WindowSeconds) * time.Second
+		}
+		wsLogSender = sender.NewWebSocketLogSender(context.Background(), cfg.LogWebSocketURL(), cfg.AgentID, cfg.AgentName, wsOpts)
+		logrus.AddHook(NewWebSocketLogHook(wsLogSender, logrus.AllLevels, levelFilter))
+		logging.SetServiceLog(wsLogSender.SendLog)
+	} else {
+		logrus.Info("Envío de logs por WebSocket deshabilitado.")
+	}
 
 	// 4. Iniciar servidor de métricas de Prometheus y UI
+	if cfg.UIAuth == nil {
+		logrus.WithField("address", cfg.MetricsListenAddress).Warn("ui_auth no está configurado: la UI y la API quedan abiertas sin autenticación.")
+	}
+	// La UI va incluida en el binario; web_dir o -web-dir la sirven desde disco para editarla sin recompilar
+	uiAssets := http.FS(web.Assets)
+	if *webDir != "" {
+		cfg.WebDir = *webDir
+	}
+	if cfg.WebDir != "" {
+		uiAssets = http.Dir(cfg.WebDir)
+		logrus.WithField("web_dir", cfg.WebDir).Info("Sirviendo la UI desde disco.")
+	}
 	go func() {
-		fs := http.FileServer(http.Dir("./web"))
+		// La UI y la API exigen ui_auth; /metrics y /healthz solo si se indica, para no romper a
+		// Prometheus ni a las sondas de salud
+		uiAuth := cfg.UIAuth
+		protect := func(enabled bool, h http.Handler) http.Handler {
+			if !enabled {
+				return h
+			}
+			return requireUIAuth(uiAuth, h)
+		}
+
+		fs := protect(true, http.FileServer(uiAssets))
 		http.Handle("/static/", http.StripPrefix("/static/", fs))
 		http.Handle("/", fs) // Sirve index.html por defecto
-		http.Handle("/metrics", promhttp.Handler())
-		http.HandleFunc("/api/current_metrics", func(w http.ResponseWriter, r *http.Request) {
+		http.Handle("/metrics", protect(uiAuth != nil && uiAuth.ProtectMetrics, promhttp.Handler()))
+
+		api := http.NewServeMux()
+		api.HandleFunc("/api/current_metrics", func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			mu.RLock() // Bloquear para lectura
-			report := latestAgentReport
+			current := latestAgentReport
 			mu.RUnlock()
 
-			if report == nil {
+			if current == nil {
 				json.NewEncoder(w).Encode(map[string]string{"error": "No metrics available yet."})
 				return
 			}
-			json.NewEncoder(w).Encode(report)
+			json.NewEncoder(w).Encode(current)
+		})
+		api.HandleFunc("/api/metrics/history", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			mu.RLock()
+			history := latestReports.list()
+			mu.RUnlock()
+			json.NewEncoder(w).Encode(history)
+		})
+		api.HandleFunc("/api/metadata", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			metadataMu.RLock()
+			defer metadataMu.RUnlock()
+			json.NewEncoder(w).Encode(collectorMetadata)
 		})
-		logrus.WithField("port", metricsPort).Info("Servidor de métricas y UI escuchando.")
-		err := http.ListenAndServe(metricsPort, nil)
-		if err != nil && err != http.ErrServerClosed {
+		api.HandleFunc("/api/version", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(version.Get())
+		})
+		api.HandleFunc("/api/stats", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			statsMu.RLock()
+			defer statsMu.RUnlock()
+			json.NewEncoder(w).Encode(agentStats)
+		})
+		http.Handle("/api/", protect(true, api))
+
+		http.Handle("/healthz", protect(uiAuth != nil && uiAuth.ProtectHealthz, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h, healthy := buildHealth(time.Duration(cfg.HealthStaleSeconds) * time.Second)
+			w.Header().Set("Content-Type", "application/json")
+			if !healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			json.NewEncoder(w).Encode(h)
+		})))
+		logrus.WithField("address", cfg.MetricsListenAddress).Info("Servidor de métricas y UI escuchando.")
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logrus.WithError(err).Fatal("Error al iniciar el servidor de métricas y UI.")
 		}
 	}()
 
-	// 5. Inicializar colectores activos
-	var activeCollectors []collector.Collector
+	// Número de secuencia persistido para que el backend detecte reportes perdidos
+	var reportSequence *state.Sequence
+	if cfg.ReportSequence && !*dryRun { // En dry-run no se consume la secuencia persistida
+		reportSequence, err = state.LoadSequence(cfg.StateFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error al cargar el estado de la secuencia de reportes.")
+		}
+		logrus.WithField("state_file", cfg.StateFile).Info("Secuencia de reportes habilitada.")
+	}
+
+	// 5. Preparar el bucle de recolección y envío (compartido por todos los colectores)
+	var wg sync.WaitGroup // Usamos un WaitGroup para esperar que todas las goroutines de colectores terminen al apagado
 
-	// Colector de métricas del sistema (siempre activo)
-	activeCollectors = append(activeCollectors, collector.NewSystemCollector(cfg))
-	logrus.Info("Colector de sistema inicializado.")
-	collectorStatus.WithLabelValues("system", cfg.AgentName, cfg.AgentID).Set(0) // Inicialmente 'down' hasta la primera recolección exitosa
+	// Crear un mapa para los últimos datos recolectados de cada tipo para la UI
+	currentCollectedData := make(map[string]report.Section)
+	var uiDataMutex sync.RWMutex // Mutex para proteger currentCollectedData
 
-	// Colector de MySQL
-	if cfg.MySQL != nil && cfg.MySQL.Enabled {
-		mysqlCollector, err := mysql.NewMySQLCollector(cfg.MySQL)
-		if err != nil {
-			logrus.WithError(err).Error("No se pudo inicializar el colector de MySQL. Será omitido.")
-			collectorStatus.WithLabelValues("mysql", cfg.AgentName, cfg.AgentID).Set(0)
+	// La identidad del host se consulta una sola vez; cada reporte lleva una copia con el uptime actual
+	hostInfo, err := collector.NewHostInfo(cfg.Hostname)
+	if err != nil {
+		logrus.WithError(err).Warn("No se pudo obtener la información del sistema. Se reportan solo el nombre de host y el sistema operativo.")
+	}
+	logrus.WithFields(logrus.Fields{"hostname": hostInfo.Hostname, "os": hostInfo.OS, "platform": hostInfo.Platform}).Debug("Información del host obtenida.")
+
+	// newReport ensambla un reporte con las secciones indicadas (nombre de colector -> métricas),
+	// anotando cuándo se recolectó cada una
+	newReport := func(sections map[string]report.Section) *report.AgentReport {
+		now := time.Now()
+		r := &report.AgentReport{
+			AgentID:      cfg.AgentID,
+			AgentName:    cfg.AgentName,
+			ReportID:     uuid.NewString(), // Clave de idempotencia: se asigna una vez y se conserva en los reintentos
+			Labels:       cfg.Labels,
+			Host:         hostInfo.At(now),
+			Timestamp:    now.Unix(),
+			AgentVersion: build.Version,
+			CollectedAt:  make(map[string]int64, len(sections)),
+		}
+		for name, section := range sections {
+			if !r.SetSection(name, section.Data) {
+				logrus.WithField("collector", name).Debug("El reporte no tiene sección para este colector.")
+				continue
+			}
+			r.CollectedAt[name] = section.CollectedAt.Unix()
+		}
+		return r
+	}
+
+	// El batcher agrupa los datos de todos los colectores en un único envío por intervalo
+	batcher := sender.NewBatcher()
+
+	// sendReport envía un reporte con los datos más recientes de cada colector
+	sendReport := func(sections map[string]report.Section) {
+		fullReport := newReport(sections)
+
+		// Medir lo que aporta cada colector al reporte ensamblado. Una sección que no se puede
+		// serializar se retira para no invalidar el reporte completo.
+		sizes, failed := fullReport.SectionSizes()
+		for name, err := range failed {
+			reportSerializationErrors.WithLabelValues(name).Inc()
+			logrus.WithField("collector", name).WithError(err).Error("Las métricas del colector no se pueden serializar a JSON. Se omiten del reporte.")
+		}
+		if len(failed) > 0 {
+			fullReport = fullReport.Filter(func(name string) bool { return failed[name] == nil })
+		}
+		statsMu.Lock()
+		for name, size := range sizes {
+			collectorPayloadBytes.WithLabelValues(name).Set(float64(size))
+			agentStats.PayloadBytes[name] = size
+		}
+		statsMu.Unlock()
+
+		// La secuencia solo avanza con reportes que se intentan enviar
+		if reportSequence != nil {
+			seq, err := reportSequence.Next()
+			if err != nil {
+				logrus.WithError(err).Warn("No se pudo persistir la secuencia de reportes.")
+			}
+			fullReport.Sequence = seq
+		}
+
+		// El último envío ocurre con mainCtx ya cancelado: se usa un contexto propio con límite
+		sendCtx := mainCtx
+		if mainCtx.Err() != nil {
+			var cancel context.CancelFunc
+			sendCtx, cancel = context.WithTimeout(context.Background(), finalFlushTimeout)
+			defer cancel()
+		}
+		err := sink.Send(sendCtx, fullReport)
+		if errors.Is(err, sender.ErrSerialization) {
+			reportSerializationErrors.WithLabelValues("report").Inc()
+		}
+		if errors.Is(err, sender.ErrBuffered) {
+			metricsSent.WithLabelValues("buffered", cfg.AgentName, cfg.AgentID).Inc()
+			logrus.Warn("Presupuesto de envío agotado. Reporte almacenado para reenvío.")
+		} else if err != nil {
+			metricsSent.WithLabelValues("failure", cfg.AgentName, cfg.AgentID).Inc()
+			logrus.WithError(err).Error("Error al enviar el reporte al backend.")
 		} else {
-			activeCollectors = append(activeCollectors, mysqlCollector)
+			metricsSent.WithLabelValues("success", cfg.AgentName, cfg.AgentID).Inc()
+			markSendSuccess()
+			logrus.WithField("collectors", len(sections)).Info("Reporte enviado exitosamente al backend.")
+		}
+	}
+
+	// runCollector ejecuta el bucle de recolección de un colector hasta que se cancele ctx.
+	// Es bloqueante: quien lo llame debe gestionar el WaitGroup.
+	runCollector := func(ctx context.Context, c collector.Collector) {
+		if d, ok := c.(collector.Describer); ok {
+			metadataMu.Lock()
+			collectorMetadata[c.Name()] = d.Metadata()
+			metadataMu.Unlock()
+		}
+
+		// Con jitter_percent los colectores de distintos agentes no disparan en los mismos instantes
+		schedule := newCollectionSchedule(c.GetInterval(), cfg.JitterPercent, cfg.JitterEveryTick)
+		timer := time.NewTimer(time.Until(schedule.next))
+		defer timer.Stop()
+
+		// Con el campo collector, log_levels también se aplica a estas líneas
+		log := logrus.WithField("collector", c.Name())
+		log.Infof("Iniciando goroutine para el colector '%s' con intervalo de %s", c.Name(), c.GetInterval())
+
+		consecutiveFailures := 0 // Fallos de recolección seguidos, se reinicia con cada éxito
+
+		for {
+			select {
+			case now := <-timer.C:
+				timer.Reset(schedule.advance(now)) // Antes de recolectar, para que los continue no lo salten
+
+				// Medir la duración de la recolección
+				start := time.Now()
+				collectedMetrics, err := c.Collect(ctx) // Recolectar métricas; ctx interrumpe la ronda al apagar
+				if ctx.Err() != nil {
+					log.Infof("Contexto cancelado durante la recolección del colector '%s'. Deteniendo.", c.Name())
+					return // El resultado de una ronda interrumpida se descarta
+				}
+
+				collectionDuration.WithLabelValues(c.Name()).Observe(time.Since(start).Seconds())
+				metricsCollected.WithLabelValues(c.Name(), cfg.AgentName, cfg.AgentID).Inc()
+
+				if err != nil {
+					// Los fallos aislados solo se advierten; el colector se marca down tras failure_threshold seguidos
+					consecutiveFailures++
+					entry := log.WithError(err)
+					// Con error_backoff, un colector que sigue fallando espera cada vez más hasta el siguiente
+					// intento y vuelve a su intervalo con el primer éxito. Es independiente de failure_threshold,
+					// que sigue decidiendo cuándo se marca down.
+					if wait := errorBackoffDelay(cfg.ErrorBackoff, c.GetInterval(), consecutiveFailures); wait > 0 {
+						schedule.postpone(now, wait)
+						timer.Reset(time.Until(schedule.next))
+						entry = entry.WithField("retry_in", wait.String())
+					}
+					if consecutiveFailures < cfg.FailureThreshold {
+						entry.Warnf("Error al recolectar métricas del colector '%s' (%d/%d fallos seguidos).", c.Name(), consecutiveFailures, cfg.FailureThreshold)
+						continue
+					}
+					entry.Errorf("Error al recolectar métricas del colector '%s'.", c.Name())
+					setCollectorState(c.Name(), cfg.AgentName, cfg.AgentID, stateFailing) // Marcar colector como down
+					continue
+				}
+				consecutiveFailures = 0
+				setCollectorState(c.Name(), cfg.AgentName, cfg.AgentID, stateUp) // Marcar colector como up
+
+				// NaN/Inf (p. ej. tasas con tiempo transcurrido cero) harían fallar json.Marshal y el envío completo
+				if n := collector.SanitizeFloats(collectedMetrics, cfg.NonFiniteFloats == "omit"); n > 0 {
+					nonFiniteValues.WithLabelValues(c.Name()).Add(float64(n))
+					log.Warnf("Se corrigieron %d valores NaN/Inf en las métricas.", n)
+				}
+
+				log.Debug("Métricas recolectadas.")
+
+				bridge.Update(c.Name(), collectedMetrics)
+
+				section := report.Section{Data: collectedMetrics, CollectedAt: time.Now()}
+
+				// Actualizar el mapa para la UI
+				uiDataMutex.Lock()
+				currentCollectedData[c.Name()] = section
+				uiDataMutex.Unlock()
+
+				uiDataMutex.RLock()
+				uiReport := newReport(currentCollectedData)
+				uiDataMutex.RUnlock()
+
+				// Actualizar la variable global latestAgentReport para la UI
+				mu.Lock()
+				latestAgentReport = uiReport // La UI obtendrá el reporte más reciente
+				latestReports.add(uiReport)
+				mu.Unlock()
+
+				// El envío al backend lo hace el batcher una vez por flush_interval_seconds
+				batcher.Update(c.Name(), section)
+
+			case <-ctx.Done(): // Apagado o detención del colector por una recarga
+				log.Infof("Contexto cancelado para el colector '%s'. Deteniendo.", c.Name())
+				return // Salir de la goroutine del colector
+			}
+		}
+	}
+
+	// Colectores en ejecución por nombre. Solo se accede desde la goroutine principal
+	// (arranque y recargas), por lo que no necesita mutex.
+	running := make(map[string]*runningCollector)
+
+	// startCollector lanza la goroutine de un colector con su propio contexto, derivado del principal,
+	// para poder detenerlo en una recarga. build obtiene el colector (puede reintentar) y
+	// la goroutine lo cierra al terminar.
+	startCollector := func(name string, build func(ctx context.Context) (collector.Collector, bool)) {
+		ctx, cancel := context.WithCancel(mainCtx)
+		rc := &runningCollector{cancel: cancel, done: make(chan struct{})}
+		running[name] = rc
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(rc.done)
+			c, ok := build(ctx)
+			if !ok {
+				return
+			}
+			runCollector(ctx, c)
+			closeCollector(c) // Ningún Collect está en curso: el bucle ya terminó
+		}()
+	}
+	ready := func(c collector.Collector) func(context.Context) (collector.Collector, bool) {
+		return func(context.Context) (collector.Collector, bool) { return c, true }
+	}
+
+	// stopCollector detiene un colector en ejecución, espera a que termine y retira sus datos
+	stopCollector := func(name string) {
+		rc, ok := running[name]
+		if !ok {
+			return
+		}
+		rc.cancel()
+		<-rc.done
+		delete(running, name)
+
+		batcher.Remove(name)
+		bridge.Remove(name)
+		uiDataMutex.Lock()
+		delete(currentCollectedData, name)
+		uiDataMutex.Unlock()
+		metadataMu.Lock()
+		delete(collectorMetadata, name)
+		metadataMu.Unlock()
+		statsMu.Lock()
+		delete(agentStats.PayloadBytes, name)
+		statsMu.Unlock()
+		collectorPayloadBytes.DeleteLabelValues(name)
+	}
+
+	// 6. Inicializar colectores activos
+	httpclient.Configure(cfg.HTTPClient) // Transporte compartido por los colectores HTTP
+	// Cada paquete de colector se registra en su init(); aquí se construyen los habilitados
+	activeCollectors := collector.BuildCollectors(cfg)
+	built := make(map[string]bool, len(activeCollectors))
+	enabled, pending := 0, 0 // Colectores habilitados y colectores aún reintentando la inicialización
+	for _, c := range activeCollectors {
+		built[c.Name()] = true
+		setCollectorState(c.Name(), cfg.AgentName, cfg.AgentID, stateStarting) // Inicialmente 'down' hasta la primera recolección exitosa
+	}
+
+	mysqlGrace := false // MySQL reintenta la inicialización en segundo plano
+	for _, r := range collector.Registrations() {
+		if !r.Enabled(cfg) {
+			continue
+		}
+		enabled++
+		if built[r.Name] {
+			continue
+		}
+		if r.Name == "mysql" && cfg.MySQL.StartupGraceSeconds > 0 {
+			// Período de gracia: MySQL puede arrancar después que el agente, reintentamos en segundo plano
+			logrus.WithField("collector", "mysql").Warnf("Reintentando la inicialización del colector de MySQL durante %s.", time.Duration(cfg.MySQL.StartupGraceSeconds)*time.Second)
+			setCollectorState("mysql", cfg.AgentName, cfg.AgentID, statePending)
+			pending++
+			mysqlGrace = true
+			continue
+		}
+		setCollectorState(r.Name, cfg.AgentName, cfg.AgentID, stateInitFailed)
+	}
+
+	// Los colectores que no pasaron por ninguna transición nunca fueron habilitados
+	for _, r := range collector.Registrations() {
+		if _, seen := getCollectorState(r.Name); !seen {
+			setCollectorState(r.Name, cfg.AgentName, cfg.AgentID, stateDisabled)
+		}
+	}
+
+	switch {
+	case enabled == 0:
+		logrus.Warn("Todos los colectores están deshabilitados en la configuración (incluido system). El agente solo servirá la UI y Prometheus.")
+	case len(activeCollectors) == 0 && pending == 0:
+		logrus.Warn("Ninguno de los colectores habilitados pudo inicializarse. El agente solo servirá la UI y Prometheus.")
+	}
+
+	// 7. Bucle principal de recolección y envío para cada colector
+	logrus.Info("Agente iniciado. Recolectando y enviando métricas...")
+
+	// Modo prometheus_only: no hay backend al que enviar
+	if sink != nil {
+		flushInterval := time.Duration(cfg.Sender.FlushIntervalSeconds) * time.Second
+		logrus.WithField("flush_interval", flushInterval.String()).Info("Envío agrupado de reportes habilitado.")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batcher.Run(mainCtx, flushInterval, sendReport)
+		}()
+	}
+
+	for _, col := range activeCollectors {
+		startCollector(col.Name(), ready(col))
+	}
+	if mysqlGrace {
+		grace := time.Duration(cfg.MySQL.StartupGraceSeconds) * time.Second
+		startCollector("mysql", func(ctx context.Context) (collector.Collector, bool) {
+			c, err := initWithGrace(ctx, grace, func() (collector.Collector, error) {
+				return mysql.NewMySQLCollector(cfg.MySQL)
+			})
+			if err != nil {
+				if ctx.Err() != nil && mainCtx.Err() == nil {
+					return nil, false // Detenido por una recarga de configuración
+				}
+				logrus.WithError(err).Error("No se pudo inicializar el colector de MySQL tras el período de gracia. Será omitido.")
+				setCollectorState("mysql", cfg.AgentName, cfg.AgentID, stateInitFailed)
+				return nil, false
+			}
 			logrus.Info("Colector de MySQL inicializado.")
-			collectorStatus.WithLabelValues("mysql", cfg.AgentName, cfg.AgentID).Set(0) // Inicialmente 'down'
+			setCollectorState("mysql", cfg.AgentName, cfg.AgentID, stateStarting)
+			return c, true
+		})
+	}
+
+	// Seguir los archivos de log configurados y enviar sus líneas por el WebSocket de logs
+	if len(cfg.LogFiles) > 0 && wsLogSender == nil {
+		logrus.Warn("log_files está configurado pero el envío de logs por WebSocket está deshabilitado. No se seguirán los archivos.")
+	}
+	for _, lf := range cfg.LogFiles {
+		if wsLogSender == nil {
+			break
 		}
+		tailer := logtail.NewTailer(lf, logging.ServiceLog)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tailer.Run(mainCtx)
+		}()
+		logrus.WithFields(logrus.Fields{"file": lf.Path, "service": lf.Service}).Info("Siguiendo archivo de log.")
 	}
 
-	// Colector de Nginx
-	if cfg.Nginx != nil && cfg.Nginx.Enabled {
-		nginxCollector, err := nginx.NewNginxCollector(cfg.Nginx)
+	// reload vuelve a leer la configuración y reinicia solo los colectores cuya configuración cambió,
+	// los recién habilitados y los habilitados que no estaban en ejecución. Si la nueva configuración
+	// no es válida se mantiene la actual. El resto de opciones (envío, servidor, logs) requieren reiniciar.
+	activeCfg := cfg
+	reload := func() {
+		// Solo lectura: una recarga nunca reescribe el archivo del operador ni imprime por stdout
+		newCfg, err := config.LoadConfigWithOptions(*configFile, config.LoadOptions{ReadOnly: true})
 		if err != nil {
-			logrus.WithError(err).Error("No se pudo inicializar el colector de Nginx. Será omitido.")
-			collectorStatus.WithLabelValues("nginx", cfg.AgentName, cfg.AgentID).Set(0)
-		} else {
-			activeCollectors = append(activeCollectors, nginxCollector)
-			logrus.Info("Colector de Nginx inicializado.")
-			collectorStatus.WithLabelValues("nginx", cfg.AgentName, cfg.AgentID).Set(0) // Inicialmente 'down'
+			logrus.WithError(err).Error("La configuración recargada no es válida. Se mantiene la configuración actual.")
+			return
 		}
+
+		restarted := 0
+		for _, r := range collector.Registrations() {
+			wasEnabled, isEnabled := r.Enabled(activeCfg), r.Enabled(newCfg)
+			_, isRunning := running[r.Name]
+			if wasEnabled == isEnabled && (!isEnabled || isRunning && !config.CollectorChanged(r.Name, activeCfg, newCfg)) {
+				continue
+			}
+			log := logrus.WithField("collector", r.Name)
+			restarted++
+
+			if isRunning {
+				stopCollector(r.Name)
+				log.Info("Colector detenido por la recarga de configuración.")
+			}
+			if !isEnabled {
+				setCollectorState(r.Name, cfg.AgentName, cfg.AgentID, stateDisabled)
+				continue
+			}
+			c, err := r.New(newCfg)
+			if err != nil {
+				log.WithError(err).Error("No se pudo inicializar el colector con la configuración recargada.")
+				setCollectorState(r.Name, cfg.AgentName, cfg.AgentID, stateInitFailed)
+				continue
+			}
+			setCollectorState(r.Name, cfg.AgentName, cfg.AgentID, stateStarting)
+			startCollector(r.Name, ready(c))
+			log.Info("Colector iniciado con la configuración recargada.")
+		}
+		activeCfg = newCfg
+		logrus.WithField("collectors_restarted", restarted).Info("Configuración recargada.")
 	}
 
-	// Colector de Procesos
-	if cfg.Process != nil && cfg.Process.Enabled {
-		processCollector, err := process.NewProcessCollector(cfg.Process)
-		if err != nil {
-			logrus.WithError(err).Error("No se pudo inicializar el colector de procesos. Será omitido.")
-			collectorStatus.WithLabelValues("process", cfg.AgentName, cfg.AgentID).Set(0)
-		} else {
-			activeCollectors = append(activeCollectors, processCollector)
-			logrus.Info("Colector de procesos inicializado.")
-			collectorStatus.WithLabelValues("process", cfg.AgentName, cfg.AgentID).Set(0) // Inicialmente 'down'
+	// La goroutine principal atiende las recargas hasta el apagado; así running y el WaitGroup
+	// solo se modifican desde aquí y nunca después de que empiece wg.Wait
+	for mainCtx.Err() == nil {
+		select {
+		case <-hupCh:
+			logrus.Info("SIGHUP recibido. Recargando la configuración...")
+			reload()
+		case <-mainCtx.Done():
 		}
 	}
+	signal.Stop(hupCh)
 
-	if len(activeCollectors) == 0 {
-		logrus.Warn("No hay colectores de métricas activos. El agente solo servirá la UI y Prometheus.")
+	// Esperar a que todas las goroutines de colectores terminen antes de salir del main;
+	// cada una cierra su colector al terminar
+	wg.Wait()
+	if sink != nil {
+		if err := sink.Close(); err != nil {
+			logrus.WithError(err).Warn("Error al cerrar el destino de reportes.")
+		}
 	}
+	logrus.Info("Todas las goroutines de colectores han terminado. Apagado completado.")
+	if wsLogSender != nil {
+		logging.SetServiceLog(nil)
+		wsLogSender.Close()
+	}
+	if logFile != nil {
+		// Lo que se registre después (ej. una goroutine rezagada) va a stdout en lugar de perderse
+		logrus.SetOutput(os.Stdout)
+		if err := logFile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error al cerrar log.file: %v\n", err)
+		}
+	}
+}
 
-	// 6. Bucle principal de recolección y envío para cada colector
-	logrus.Info("Agente iniciado. Recolectando y enviando métricas...")
+// newLogFormatter elige el formato de los logs según log.format. Sin valor se usa texto cuando los
+// logs van a una terminal, más legible al ejecutar el agente a mano, y JSON en otro caso.
+func newLogFormatter(cfg *config.LogConfig) logrus.Formatter {
+	format := ""
+	toFile := false
+	if cfg != nil {
+		format = cfg.Format
+		toFile = cfg.File != ""
+	}
+	if format == "" {
+		format = "json"
+		if info, err := os.Stdout.Stat(); err == nil && !toFile && info.Mode()&os.ModeCharDevice != 0 {
+			format = "text"
+		}
+	}
+	if format == "text" {
+		return &logrus.TextFormatter{FullTimestamp: true}
+	}
+	return &logrus.JSONFormatter{}
+}
 
-	var wg sync.WaitGroup // Usamos un WaitGroup para esperar que todas las goroutines de colectores terminen al apagado
+// runningCollector es la goroutine de un colector en ejecución, que puede detenerse por separado
+type runningCollector struct {
+	cancel context.CancelFunc // Cancela el contexto propio del colector
+	done   chan struct{}      // Se cierra cuando la goroutine termina y el colector está cerrado
+}
 
-	// Crear un mapa para los últimos datos recolectados de cada tipo para la UI
-	currentCollectedData := make(map[string]interface{})
-	var uiDataMutex sync.RWMutex // Mutex para proteger currentCollectedData
+// collectorLifecycle es el estado de un colector a lo largo de su ciclo de vida
+type collectorLifecycle string
 
-	for _, col := range activeCollectors {
-		wg.Add(1) // Añadir uno al WaitGroup por cada goroutine de colector
-		go func(c collector.Collector) {
-			defer wg.Done() // Asegurar que Done() se llama cuando la goroutine termina
+const (
+	stateDisabled   collectorLifecycle = "disabled"    // No habilitado en la configuración
+	statePending    collectorLifecycle = "pending"     // Reintentando la inicialización (período de gracia)
+	stateInitFailed collectorLifecycle = "init_failed" // Habilitado pero la inicialización falló
+	stateStarting   collectorLifecycle = "starting"    // Inicializado, aún sin recolecciones
+	stateUp         collectorLifecycle = "up"          // Última recolección exitosa
+	stateFailing    collectorLifecycle = "failing"     // Última recolección fallida
+)
 
-			ticker := time.NewTicker(c.GetInterval())
-			defer ticker.Stop()
+var allCollectorStates = []collectorLifecycle{stateDisabled, statePending, stateInitFailed, stateStarting, stateUp, stateFailing}
 
-			logrus.Infof("Iniciando goroutine para el colector '%s' con intervalo de %s", c.Name(), c.GetInterval())
+// Último estado conocido de cada colector y momento de su última recolección exitosa
+var collectorStates = make(map[string]collectorLifecycle)
+var collectorLastSuccess = make(map[string]time.Time)
+var statesMu sync.RWMutex // Mutex para proteger collectorStates y collectorLastSuccess
 
-			for {
-				select {
-				case <-ticker.C:
-					// Medir la duración de la recolección
-					start := time.Now()
-					collectedMetrics, err := c.Collect() // Recolectar métricas
+// setCollectorState actualiza agent_collector_state y agent_collector_status (1 solo cuando está up)
+func setCollectorState(name, agentName, agentID string, st collectorLifecycle) {
+	statesMu.Lock()
+	collectorStates[name] = st
+	if st == stateUp {
+		collectorLastSuccess[name] = time.Now()
+	}
+	statesMu.Unlock()
 
-					collectionDuration.WithLabelValues(c.Name()).Observe(time.Since(start).Seconds())
-					metricsCollected.WithLabelValues(c.Name(), cfg.AgentName, cfg.AgentID).Inc()
+	for _, candidate := range allCollectorStates {
+		value := 0.0
+		if candidate == st {
+			value = 1
+		}
+		collectorState.WithLabelValues(name, agentName, agentID, string(candidate)).Set(value)
+	}
 
-					if err != nil {
-						logrus.WithError(err).Errorf("Error al recolectar métricas del colector '%s'.", c.Name())
-						collectorStatus.WithLabelValues(c.Name(), cfg.AgentName, cfg.AgentID).Set(0) // Marcar colector como down
-						continue
-					}
-					collectorStatus.WithLabelValues(c.Name(), cfg.AgentName, cfg.AgentID).Set(1) // Marcar colector como up
+	status := 0.0
+	if st == stateUp {
+		status = 1
+	}
+	collectorStatus.WithLabelValues(name, agentName, agentID).Set(status)
+}
 
-					logrus.WithField("collector_name", c.Name()).Debug("Métricas recolectadas.")
+// getCollectorState devuelve el último estado de un colector y si alguna vez se estableció
+func getCollectorState(name string) (collectorLifecycle, bool) {
+	statesMu.RLock()
+	defer statesMu.RUnlock()
+	st, ok := collectorStates[name]
+	return st, ok
+}
 
-					// Actualizar el mapa para la UI
-					uiDataMutex.Lock()
-					currentCollectedData[c.Name()] = collectedMetrics
-					uiDataMutex.Unlock()
+// collectorHealth es el estado de un colector en /healthz
+type collectorHealth struct {
+	Status      string     `json:"status"` // up o down, como agent_collector_status
+	State       string     `json:"state"`  // Estado detallado del ciclo de vida
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
 
-					fullReport := &AgentReport{
-						AgentID:   cfg.AgentID,
-						AgentName: cfg.AgentName,
-						Timestamp: time.Now().Unix(),
-					}
+// healthReport es la respuesta de /healthz
+type healthReport struct {
+	Status             string                     `json:"status"` // ok o unhealthy
+	UptimeSeconds      int64                      `json:"uptime_seconds"`
+	LastSuccessfulSend *time.Time                 `json:"last_successful_send,omitempty"`
+	Collectors         map[string]collectorHealth `json:"collectors"` // Solo colectores habilitados
+}
 
-					uiDataMutex.RLock()
-					if sysMetrics, ok := currentCollectedData["system"].(*collector.SystemMetrics); ok {
-						fullReport.System = sysMetrics
-					}
-					if mysqlMetrics, ok := currentCollectedData["mysql"].(*mysql.MySQLMetrics); ok {
-						fullReport.MySQL = mysqlMetrics
-					}
-					if nginxMetrics, ok := currentCollectedData["nginx"].(*nginx.NginxMetrics); ok {
-						fullReport.Nginx = nginxMetrics
-					}
-					if processMetrics, ok := currentCollectedData["process"].(*process.ProcessMetrics); ok {
-						fullReport.Process = processMetrics
-					}
-					// ... añadir más tipos de métricas aquí ...
-					uiDataMutex.RUnlock()
-
-					// Actualizar la variable global latestAgentReport para la UI
-					mu.Lock()
-					latestAgentReport = fullReport // La UI obtendrá el reporte más reciente
-					mu.Unlock()
-
-					// Enviar métricas
-					err = httpSender.Send(fullReport)
-					if err != nil {
-						metricsSent.WithLabelValues("failure", cfg.AgentName, cfg.AgentID).Inc()
-						logrus.WithError(err).Errorf("Error al enviar métricas de '%s' al backend.", c.Name())
-					} else {
-						metricsSent.WithLabelValues("success", cfg.AgentName, cfg.AgentID).Inc()
-						logrus.Infof("Métricas de '%s' enviadas exitosamente al backend.", c.Name())
-					}
+// Momento del arranque y del último envío aceptado por el backend, para /healthz
+var agentStartTime = time.Now()
+var lastSuccessfulSend time.Time
+var sendMu sync.RWMutex // Mutex para proteger lastSuccessfulSend
 
-				case <-mainCtx.Done(): // Referencia al contexto principal
-					logrus.Infof("Contexto cancelado para el colector '%s'. Deteniendo.", c.Name())
-					return // Salir de la goroutine del colector
-				}
+// markSendSuccess registra un envío aceptado por el backend
+func markSendSuccess() {
+	sendMu.Lock()
+	lastSuccessfulSend = time.Now()
+	sendMu.Unlock()
+}
+
+// buildHealth evalúa la salud del agente: está sano si algún colector tuvo éxito en los últimos
+// stale, o si arrancó hace menos de stale y aún no hubo tiempo de recolectar.
+func buildHealth(stale time.Duration) (healthReport, bool) {
+	now := time.Now()
+	h := healthReport{
+		Status:        "ok",
+		UptimeSeconds: int64(now.Sub(agentStartTime).Seconds()),
+		Collectors:    make(map[string]collectorHealth),
+	}
+
+	sendMu.RLock()
+	if !lastSuccessfulSend.IsZero() {
+		sent := lastSuccessfulSend
+		h.LastSuccessfulSend = &sent
+	}
+	sendMu.RUnlock()
+
+	healthy := now.Sub(agentStartTime) < stale
+	statesMu.RLock()
+	for name, st := range collectorStates {
+		if st == stateDisabled {
+			continue
+		}
+		ch := collectorHealth{Status: "down", State: string(st)}
+		if st == stateUp {
+			ch.Status = "up"
+		}
+		if last, ok := collectorLastSuccess[name]; ok {
+			ch.LastSuccess = &last
+			if now.Sub(last) < stale {
+				healthy = true
 			}
-		}(col) // Pasar el colector a la goroutine
+		}
+		h.Collectors[name] = ch
 	}
+	statesMu.RUnlock()
 
-	// Esperar a que todas las goroutines de colectores terminen antes de salir del main
-	wg.Wait()
-	logrus.Info("Todas las goroutines de colectores han terminado. Apagado completado.")
+	if !healthy {
+		h.Status = "unhealthy"
+	}
+	return h, healthy
+}
+
+// requireUIAuth exige las credenciales de ui_auth antes de llamar a next. Con auth nil la ruta
+// queda abierta. Las comparaciones son de tiempo constante para no filtrar los secretos.
+func requireUIAuth(auth *config.UIAuthConfig, next http.Handler) http.Handler {
+	if auth == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.Token != "" {
+			if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && secretEqual(token, auth.Token) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if auth.Username != "" {
+			if user, pass, ok := r.BasicAuth(); ok && secretEqual(user, auth.Username) && secretEqual(pass, auth.Password) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// El navegador pide usuario y contraseña al recibir este desafío
+			w.Header().Add("WWW-Authenticate", `Basic realm="logtick-agent", charset="UTF-8"`)
+		}
+		if auth.Token != "" {
+			w.Header().Add("WWW-Authenticate", `Bearer realm="logtick-agent"`)
+		}
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+	})
+}
+
+// secretEqual compara dos secretos en tiempo constante
+func secretEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// closeCollector libera los recursos de un colector, registrando el error si lo hay
+func closeCollector(c collector.Collector) {
+	if err := c.Close(); err != nil {
+		logrus.WithError(err).WithField("collector", c.Name()).Warn("Error al cerrar el colector.")
+	}
+}
+
+// collectionSchedule calcula los disparos de un colector: uno por intervalo, como time.Ticker, con el
+// primero (y con everyTick, cada uno) desplazado al azar hasta ±jitter del intervalo
+type collectionSchedule struct {
+	interval  time.Duration
+	jitter    float64 // Fracción del intervalo (jitter_percent / 100)
+	everyTick bool
+	next      time.Time // Próximo disparo
+}
+
+func newCollectionSchedule(interval time.Duration, jitterPercent float64, everyTick bool) *collectionSchedule {
+	s := &collectionSchedule{interval: interval, jitter: jitterPercent / 100, everyTick: everyTick}
+	s.next = time.Now().Add(s.jittered())
+	return s
+}
+
+// jittered devuelve el intervalo desplazado al azar dentro de ±jitter
+func (s *collectionSchedule) jittered() time.Duration {
+	if s.jitter <= 0 {
+		return s.interval
+	}
+	offset := (rand.Float64()*2 - 1) * s.jitter
+	return time.Duration(float64(s.interval) * (1 + offset))
+}
+
+// advance programa el disparo siguiente al de now y devuelve cuánto falta. Los disparos que una
+// recolección más lenta que el intervalo dejó atrás se saltan, como hace time.Ticker.
+func (s *collectionSchedule) advance(now time.Time) time.Duration {
+	step := s.interval
+	if s.everyTick {
+		step = s.jittered()
+	}
+	s.next = s.next.Add(step)
+	for !s.next.After(now) {
+		s.next = s.next.Add(s.interval)
+	}
+	return s.next.Sub(now)
+}
+
+// postpone programa el siguiente disparo wait después de now, en lugar del intervalo normal. Los
+// disparos posteriores siguen cada intervalo a partir de él.
+func (s *collectionSchedule) postpone(now time.Time, wait time.Duration) {
+	s.next = now.Add(wait)
+}
+
+// errorBackoffDelay devuelve cuánto esperar tras failures fallos seguidos, o 0 para seguir con el
+// intervalo normal. Desde after_failures fallos el intervalo se duplica con cada fallo, sin superar
+// max_interval_seconds.
+func errorBackoffDelay(cfg *config.ErrorBackoffConfig, interval time.Duration, failures int) time.Duration {
+	if cfg == nil || failures < cfg.AfterFailures {
+		return 0
+	}
+	max := time.Duration(cfg.MaxIntervalSeconds) * time.Second
+	wait := interval
+	for i := cfg.AfterFailures; i <= failures && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	if wait <= interval {
+		return 0 // max_interval_seconds no supera el intervalo del colector
+	}
+	return wait
+}
+
+// initRetryInterval es la pausa entre reintentos de inicialización durante el período de gracia.
+const initRetryInterval = 5 * time.Second
+
+// initWithGrace reintenta la inicialización de un colector hasta que tenga éxito,
+// se agote el período de gracia o se cancele el contexto.
+func initWithGrace(ctx context.Context, grace time.Duration, newCollector func() (collector.Collector, error)) (collector.Collector, error) {
+	deadline := time.Now().Add(grace)
+	ticker := time.NewTicker(initRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			c, err := newCollector()
+			if err == nil {
+				return c, nil
+			}
+			if time.Now().After(deadline) {
+				return nil, fmt.Errorf("período de gracia de %s agotado: %w", grace, err)
+			}
+			logrus.WithError(err).Debug("Reintento de inicialización fallido.")
+		}
+	}
 }
@@ -0,0 +1,201 @@
+package promexport
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/atrox39/logtick/collector"
+)
+
+// metricPrefix es el prefijo de todas las métricas expuestas por el puente
+const metricPrefix = "logtick"
+
+// sample es un valor numérico aplanado a partir de las métricas de un colector
+type sample struct {
+	name        string
+	field       string // Etiqueta JSON del campo del que procede el valor
+	labelNames  []string
+	labelValues []string
+	value       float64
+}
+
+// Bridge expone los valores recolectados por los colectores como métricas de Prometheus.
+// Los nombres se derivan de las etiquetas JSON de cada campo
+// (ej. logtick_mysql_threads_connected) y las claves de mapas e índices
+// de listas se convierten en etiquetas.
+type Bridge struct {
+	mu          sync.RWMutex
+	samples     map[string][]sample // Últimos valores por colector
+	constLabels prometheus.Labels
+}
+
+// NewBridge crea un puente con etiquetas constantes añadidas a todas las métricas
+func NewBridge(constLabels prometheus.Labels) *Bridge {
+	return &Bridge{
+		samples:     make(map[string][]sample),
+		constLabels: constLabels,
+	}
+}
+
+// Update reemplaza los valores de un colector con los recién recolectados
+func (b *Bridge) Update(collectorName string, data collector.MetricData) {
+	var out []sample
+	flatten(reflect.ValueOf(data), []string{metricPrefix, collectorName}, nil, nil, &out)
+
+	b.mu.Lock()
+	b.samples[collectorName] = out
+	b.mu.Unlock()
+}
+
+// Remove deja de exponer los valores de un colector que se ha detenido
+func (b *Bridge) Remove(collectorName string) {
+	b.mu.Lock()
+	delete(b.samples, collectorName)
+	b.mu.Unlock()
+}
+
+// Sample es un valor aplanado con el mismo nombre y etiquetas que expone el puente,
+// para exportadores que no pasan por /metrics (ej. remote-write)
+type Sample struct {
+	Name   string
+	Field  string // Etiqueta JSON del campo, coincide con collector.MetricDescriptor.Name
+	Labels map[string]string
+	Value  float64
+}
+
+// Flatten aplana las métricas de un colector con las mismas reglas que el puente
+func Flatten(collectorName string, data interface{}) []Sample {
+	var raw []sample
+	flatten(reflect.ValueOf(data), []string{metricPrefix, collectorName}, nil, nil, &raw)
+
+	out := make([]Sample, len(raw))
+	for i, s := range raw {
+		labels := make(map[string]string, len(s.labelNames))
+		for j, name := range s.labelNames {
+			labels[name] = s.labelValues[j]
+		}
+		out[i] = Sample{Name: s.name, Field: s.field, Labels: labels, Value: s.value}
+	}
+	return out
+}
+
+// Describe no declara descriptores: el conjunto de métricas depende de los datos
+// recolectados, por lo que el puente se registra como colector "unchecked".
+func (b *Bridge) Describe(chan<- *prometheus.Desc) {}
+
+// Collect emite los últimos valores de todos los colectores
+func (b *Bridge) Collect(ch chan<- prometheus.Metric) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, samples := range b.samples {
+		for _, s := range samples {
+			desc := prometheus.NewDesc(s.name, "Value collected by logtick.", s.labelNames, b.constLabels)
+			m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, s.value, s.labelValues...)
+			if err != nil {
+				continue
+			}
+			ch <- m
+		}
+	}
+}
+
+// flatten recorre una estructura de métricas y acumula sus valores numéricos
+func flatten(v reflect.Value, nameParts, labelNames, labelValues []string, out *[]sample) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			flatten(v.Elem(), nameParts, labelNames, labelValues, out)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			flatten(v.Field(i), append(nameParts[:len(nameParts):len(nameParts)], name), labelNames, labelValues, out)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		label := labelName(labelNames, "key")
+		iter := v.MapRange()
+		for iter.Next() {
+			flatten(iter.Value(), nameParts, appendCopy(labelNames, label), appendCopy(labelValues, iter.Key().String()), out)
+		}
+	case reflect.Slice, reflect.Array:
+		label := labelName(labelNames, "index")
+		for i := 0; i < v.Len(); i++ {
+			flatten(v.Index(i), nameParts, appendCopy(labelNames, label), appendCopy(labelValues, strconv.Itoa(i)), out)
+		}
+	case reflect.Bool:
+		value := 0.0
+		if v.Bool() {
+			value = 1
+		}
+		emit(nameParts, labelNames, labelValues, value, out)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		emit(nameParts, labelNames, labelValues, float64(v.Int()), out)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		emit(nameParts, labelNames, labelValues, float64(v.Uint()), out)
+	case reflect.Float32, reflect.Float64:
+		emit(nameParts, labelNames, labelValues, v.Float(), out)
+	}
+}
+
+func emit(nameParts, labelNames, labelValues []string, value float64, out *[]sample) {
+	*out = append(*out, sample{
+		name:        sanitize(strings.Join(nameParts, "_")),
+		field:       nameParts[len(nameParts)-1],
+		labelNames:  labelNames,
+		labelValues: labelValues,
+		value:       value,
+	})
+}
+
+// labelName evita colisiones cuando hay mapas o listas anidados (key, key_2, ...)
+func labelName(existing []string, base string) string {
+	name := base
+	for n := 2; contains(existing, name); n++ {
+		name = base + "_" + strconv.Itoa(n)
+	}
+	return name
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func appendCopy(list []string, s string) []string {
+	out := make([]string, len(list), len(list)+1)
+	copy(out, list)
+	return append(out, s)
+}
+
+// sanitize reemplaza los caracteres no válidos en nombres de métricas de Prometheus
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"time"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/collector/conntrack"
+	"github.com/atrox39/logtick/collector/cri"
+	"github.com/atrox39/logtick/collector/diskio"
+	"github.com/atrox39/logtick/collector/dns"
+	"github.com/atrox39/logtick/collector/elasticsearch"
+	"github.com/atrox39/logtick/collector/haproxy"
+	"github.com/atrox39/logtick/collector/jolokia"
+	"github.com/atrox39/logtick/collector/mongodb"
+	"github.com/atrox39/logtick/collector/mysql"
+	"github.com/atrox39/logtick/collector/nginx"
+	"github.com/atrox39/logtick/collector/ports"
+	"github.com/atrox39/logtick/collector/postgres"
+	"github.com/atrox39/logtick/collector/process"
+	"github.com/atrox39/logtick/collector/promscrape"
+	"github.com/atrox39/logtick/collector/sensors"
+	"github.com/atrox39/logtick/collector/smart"
+	"github.com/atrox39/logtick/collector/windows"
+)
+
+// AgentReport encapsula todas las métricas recolectadas para un envío consolidado
+type AgentReport struct {
+	AgentID       string                              `json:"agent_id"`
+	AgentName     string                              `json:"agent_name"`
+	ReportID      string                              `json:"report_id,omitempty"` // UUID único del reporte; se repite en los reintentos
+	Labels        map[string]string                   `json:"labels,omitempty"`    // Etiquetas definidas en la configuración
+	Host          *collector.HostInfo                 `json:"host,omitempty"`      // Máquina en la que corre el agente
+	Timestamp     int64                               `json:"timestamp"`
+	AgentVersion  string                              `json:"agent_version,omitempty"` // Versión de compilación del agente
+	Sequence      uint64                              `json:"sequence,omitempty"`      // Monótono por agente, persiste entre reinicios
+	CollectedAt   map[string]int64                    `json:"collected_at,omitempty"`  // Momento de recolección (Unix) de cada sección, por nombre de colector
+	System        *collector.SystemMetrics            `json:"system_metrics,omitempty"`
+	MySQL         *mysql.MySQLMetrics                 `json:"mysql_metrics,omitempty"`
+	Nginx         *nginx.NginxMetrics                 `json:"nginx_metrics,omitempty"`
+	Process       *process.ProcessMetrics             `json:"process_metrics,omitempty"`
+	Smart         *smart.SmartMetrics                 `json:"smart_metrics,omitempty"`
+	MongoDB       *mongodb.MongoDBMetrics             `json:"mongodb_metrics,omitempty"`
+	Elasticsearch *elasticsearch.ElasticsearchMetrics `json:"elasticsearch_metrics,omitempty"`
+	Conntrack     *conntrack.ConntrackMetrics         `json:"conntrack_metrics,omitempty"`
+	Sensors       *sensors.SensorsMetrics             `json:"sensors_metrics,omitempty"`
+	Windows       *windows.WindowsMetrics             `json:"windows_metrics,omitempty"`
+	Jolokia       *jolokia.JolokiaMetrics             `json:"jolokia_metrics,omitempty"`
+	CRI           *cri.CRIMetrics                     `json:"cri_metrics,omitempty"`
+	HAProxy       *haproxy.HAProxyMetrics             `json:"haproxy_metrics,omitempty"`
+	DNS           *dns.DNSMetrics                     `json:"dns_metrics,omitempty"`
+	PromScrape    *promscrape.PromScrapeMetrics       `json:"promscrape_metrics,omitempty"`
+	Ports         *ports.PortsMetrics                 `json:"ports_metrics,omitempty"`
+	Postgres      *postgres.PostgresMetrics           `json:"postgres_metrics,omitempty"`
+	DiskIO        *diskio.DiskIOMetrics               `json:"diskio_metrics,omitempty"`
+	// Añadir más tipos de métricas aquí según se implementen los colectores
+}
+
+// Section son las métricas de un colector junto con el momento en que se recolectaron
+type Section struct {
+	Data        interface{}
+	CollectedAt time.Time
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// sectionSuffix termina la etiqueta JSON de cada sección de colector (ej. "mysql_metrics")
+const sectionSuffix = "_metrics"
+
+// Sections devuelve las secciones presentes en el reporte indexadas por nombre de colector
+func (r *AgentReport) Sections() map[string]interface{} {
+	sections := make(map[string]interface{})
+	v := reflect.ValueOf(r).Elem()
+	for i, name := range sectionFields() {
+		if name == "" {
+			continue
+		}
+		if f := v.Field(i); !f.IsNil() {
+			sections[name] = f.Interface()
+		}
+	}
+	return sections
+}
+
+// SetSection asigna las métricas de un colector a su sección del reporte.
+// Devuelve false si no existe una sección con ese nombre o si el tipo de data no coincide.
+func (r *AgentReport) SetSection(collectorName string, data interface{}) bool {
+	v := reflect.ValueOf(r).Elem()
+	for i, name := range sectionFields() {
+		if name != collectorName {
+			continue
+		}
+		value := reflect.ValueOf(data)
+		if !value.IsValid() || value.Type() != v.Field(i).Type() {
+			return false
+		}
+		v.Field(i).Set(value)
+		return true
+	}
+	return false
+}
+
+// SectionSizes serializa a JSON cada sección presente en el reporte y devuelve su tamaño en bytes
+// por nombre de colector. Las secciones que no se pueden serializar se devuelven en failed con su error.
+func (r *AgentReport) SectionSizes() (sizes map[string]int, failed map[string]error) {
+	sizes = make(map[string]int)
+	for name, data := range r.Sections() {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			if failed == nil {
+				failed = make(map[string]error)
+			}
+			failed[name] = err
+			continue
+		}
+		sizes[name] = len(payload)
+	}
+	return sizes, failed
+}
+
+// Filter devuelve una copia del reporte que conserva solo las secciones de los colectores
+// para los que keep devuelve true, junto con sus marcas de recolección. Los campos de identidad
+// se copian siempre.
+func (r *AgentReport) Filter(keep func(collectorName string) bool) *AgentReport {
+	filtered := *r
+	v := reflect.ValueOf(&filtered).Elem()
+	for i, name := range sectionFields() {
+		if name != "" && !keep(name) {
+			v.Field(i).Set(reflect.Zero(v.Field(i).Type()))
+		}
+	}
+	if r.CollectedAt != nil {
+		filtered.CollectedAt = make(map[string]int64)
+		for name, ts := range r.CollectedAt {
+			if keep(name) {
+				filtered.CollectedAt[name] = ts
+			}
+		}
+	}
+	return &filtered
+}
+
+// sectionFields devuelve, por índice de campo de AgentReport, el nombre del colector de esa
+// sección o "" si el campo no es una sección
+func sectionFields() []string {
+	t := reflect.TypeOf(AgentReport{})
+	names := make([]string, t.NumField())
+	for i := range names {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if strings.HasSuffix(tag, sectionSuffix) && t.Field(i).Type.Kind() == reflect.Ptr {
+			names[i] = strings.TrimSuffix(tag, sectionSuffix)
+		}
+	}
+	return names
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/collector/mysql"
+)
+
+func TestSectionSizes(t *testing.T) {
+	mysqlMetrics := &mysql.MySQLMetrics{ThreadsConnected: 4}
+	r := &AgentReport{
+		AgentID: "a",
+		System:  &collector.SystemMetrics{CPUPercent: math.NaN()}, // NaN no se puede serializar a JSON
+		MySQL:   mysqlMetrics,
+	}
+
+	sizes, failed := r.SectionSizes()
+
+	want, err := json.Marshal(mysqlMetrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 1 || sizes["mysql"] != len(want) {
+		t.Errorf("sizes = %v, se esperaba map[mysql:%d]", sizes, len(want))
+	}
+	if len(failed) != 1 || failed["system"] == nil {
+		t.Errorf("failed = %v, se esperaba un error para system", failed)
+	}
+}
+
+func TestSectionSizesEmptyReport(t *testing.T) {
+	sizes, failed := (&AgentReport{AgentID: "a"}).SectionSizes()
+	if len(sizes) != 0 || failed != nil {
+		t.Errorf("SectionSizes() = %v, %v; se esperaba un mapa vacío y sin errores", sizes, failed)
+	}
+}
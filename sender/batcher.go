@@ -0,0 +1,79 @@
+package sender
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/atrox39/logtick/report"
+)
+
+// Batcher conserva el dato más reciente de cada colector y los entrega agrupados una vez por
+// intervalo, de modo que la cadencia de envío no depende de cuántos colectores haya ni de sus
+// intervalos. Si ningún colector aportó datos nuevos desde la entrega anterior, no entrega nada.
+type Batcher struct {
+	mu      sync.Mutex
+	latest  map[string]report.Section // Último dato por nombre de colector
+	changed bool                      // Hubo Update desde la última entrega
+}
+
+// NewBatcher crea un Batcher vacío
+func NewBatcher() *Batcher {
+	return &Batcher{latest: make(map[string]report.Section)}
+}
+
+// Update guarda el dato más reciente de un colector, sustituyendo al anterior
+func (b *Batcher) Update(collectorName string, section report.Section) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.latest[collectorName] = section
+	b.changed = true
+}
+
+// Remove descarta el dato de un colector que se ha detenido para que no se siga enviando
+func (b *Batcher) Remove(collectorName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.latest[collectorName]; ok {
+		delete(b.latest, collectorName)
+		b.changed = true
+	}
+}
+
+// take devuelve una copia de los datos actuales si cambiaron desde la llamada anterior
+func (b *Batcher) take() (map[string]report.Section, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.changed {
+		return nil, false
+	}
+	b.changed = false
+	sections := make(map[string]report.Section, len(b.latest))
+	for name, section := range b.latest {
+		sections[name] = section
+	}
+	return sections, true
+}
+
+// Run entrega los datos agrupados a flush cada interval hasta que se cancele ctx. Al cancelarse
+// entrega una última vez lo pendiente, para no perder la última recolección al apagar.
+// Es bloqueante; flush se llama siempre desde la goroutine de Run.
+func (b *Batcher) Run(ctx context.Context, interval time.Duration, flush func(sections map[string]report.Section)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if sections, ok := b.take(); ok {
+				flush(sections)
+			}
+		case <-ctx.Done():
+			if sections, ok := b.take(); ok {
+				flush(sections)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package sender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/atrox39/logtick/report"
+)
+
+func TestBatcherRunFlushesPendingOnCancel(t *testing.T) {
+	b := NewBatcher()
+	b.Update("system", report.Section{Data: map[string]int{"cpu": 1}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var flushed []map[string]report.Section
+	// Con un intervalo largo el ticker no llega a dispararse: solo queda la entrega final
+	b.Run(ctx, time.Hour, func(sections map[string]report.Section) {
+		flushed = append(flushed, sections)
+	})
+
+	if len(flushed) != 1 {
+		t.Fatalf("flush llamado %d veces, se esperaba 1", len(flushed))
+	}
+	if _, ok := flushed[0]["system"]; !ok {
+		t.Errorf("la entrega final no incluye system: %v", flushed[0])
+	}
+}
+
+func TestBatcherRunSkipsFinalFlushWithoutChanges(t *testing.T) {
+	b := NewBatcher()
+	b.Update("system", report.Section{})
+	b.take() // Ya entregado
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	b.Run(ctx, time.Hour, func(map[string]report.Section) { calls++ })
+	if calls != 0 {
+		t.Errorf("flush llamado %d veces sin datos nuevos, se esperaba 0", calls)
+	}
+}
@@ -0,0 +1,57 @@
+package sender
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BudgetUtilization indica la fracción del presupuesto de envíos consumida (0 = lleno, 1 = agotado).
+// Se registra en Prometheus desde main.
+var BudgetUtilization = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "agent_sender_budget_utilization",
+	Help: "Fraction of the shared send budget currently consumed (0 = full, 1 = exhausted).",
+})
+
+// Budget es un token bucket compartido por todos los intentos de envío.
+// Acota la tasa agregada de salida sin importar cuántos colectores o reportes pendientes haya.
+type Budget struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens repuestos por segundo
+	burst  float64 // Capacidad máxima del bucket
+	tokens float64
+	last   time.Time
+}
+
+// NewBudget crea un presupuesto lleno con la tasa y ráfaga indicadas
+func NewBudget(perSecond float64, burst int) *Budget {
+	b := &Budget{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+	BudgetUtilization.Set(0)
+	return b
+}
+
+// Allow consume un token si hay disponible. No bloquea.
+func (b *Budget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	BudgetUtilization.Set(1 - b.tokens/b.burst)
+	return allowed
+}
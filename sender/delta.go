@@ -0,0 +1,100 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// identityFields se incluyen siempre en los reportes delta para que el backend los asocie
+var identityFields = []string{"agent_id", "agent_name", "report_id", "labels", "timestamp", "sequence"}
+
+// deltaEncoder convierte reportes completos en parches JSON Merge Patch (RFC 7386)
+// respecto al último reporte aceptado por el backend. Cada fullEvery se envía un reporte
+// completo para que el backend pueda resincronizarse.
+type deltaEncoder struct {
+	mu        sync.Mutex // Serializa los envíos: cada delta se calcula sobre el último aceptado
+	fullEvery time.Duration
+	last      map[string]interface{} // Último reporte aceptado (nil = enviar completo)
+	lastFull  time.Time
+}
+
+// newDeltaEncoder crea un codificador que fuerza un reporte completo cada fullEvery
+func newDeltaEncoder(fullEvery time.Duration) *deltaEncoder {
+	return &deltaEncoder{fullEvery: fullEvery}
+}
+
+// encode devuelve el cuerpo a enviar y una función que debe invocarse si el backend lo acepta.
+// El llamador debe mantener d.mu bloqueado desde encode hasta la confirmación.
+func (d *deltaEncoder) encode(full []byte) ([]byte, func(), error) {
+	current, err := decodeObject(full)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if d.last == nil || now.Sub(d.lastFull) >= d.fullEvery {
+		return full, func() {
+			d.last = current
+			d.lastFull = now
+		}, nil
+	}
+
+	patch := mergePatch(d.last, current)
+	for _, field := range identityFields {
+		if v, ok := current[field]; ok {
+			patch[field] = v
+		}
+	}
+	patch["delta"] = true
+
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al serializar el reporte delta: %w: %w", ErrSerialization, err)
+	}
+	return body, func() { d.last = current }, nil
+}
+
+// decodeObject decodifica un objeto JSON conservando los números tal cual para compararlos sin pérdida
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("error al decodificar el reporte para calcular el delta: %w", err)
+	}
+	return obj, nil
+}
+
+// mergePatch calcula el parche que transforma prev en cur: los campos nuevos o modificados
+// se incluyen, los eliminados se marcan con null y los objetos se comparan recursivamente.
+func mergePatch(prev, cur map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for k, v := range cur {
+		old, existed := prev[k]
+		if !existed {
+			patch[k] = v
+			continue
+		}
+		oldObj, oldIsObj := old.(map[string]interface{})
+		newObj, newIsObj := v.(map[string]interface{})
+		if oldIsObj && newIsObj {
+			if sub := mergePatch(oldObj, newObj); len(sub) > 0 {
+				patch[k] = sub
+			}
+			continue
+		}
+		if !reflect.DeepEqual(old, v) {
+			patch[k] = v
+		}
+	}
+	for k := range prev {
+		if _, ok := cur[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
@@ -0,0 +1,189 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/atrox39/logtick/report"
+)
+
+// Content-Type de cada formato de salida
+const (
+	ContentTypeJSON         = "application/json"
+	ContentTypeMsgpack      = "application/msgpack"
+	ContentTypeLineProtocol = "text/plain; charset=utf-8"
+)
+
+// Encoder serializa un reporte en el formato de salida de un enviador HTTP
+type Encoder interface {
+	// Encode devuelve el cuerpo y su Content-Type. Los errores envuelven ErrSerialization.
+	Encode(r *report.AgentReport) (body []byte, contentType string, err error)
+}
+
+// NewEncoder devuelve el Encoder de un output_format: json, msgpack o line_protocol
+func NewEncoder(format string) (Encoder, error) {
+	switch format {
+	case "", "json":
+		return JSONEncoder{}, nil
+	case "msgpack":
+		return MsgpackEncoder{}, nil
+	case "line_protocol":
+		return LineProtocolEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("formato de salida no soportado: %s", format)
+	}
+}
+
+// JSONEncoder serializa el reporte como JSON (formato por defecto)
+type JSONEncoder struct{}
+
+// Encode implementa la interfaz Encoder
+func (JSONEncoder) Encode(r *report.AgentReport) ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("error al serializar los datos a JSON: %w: %w", ErrSerialization, err)
+	}
+	return data, ContentTypeJSON, nil
+}
+
+// MsgpackEncoder serializa el reporte como MessagePack con los mismos nombres de campo que el JSON
+type MsgpackEncoder struct{}
+
+// Encode implementa la interfaz Encoder
+func (MsgpackEncoder) Encode(r *report.AgentReport) ([]byte, string, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(r); err != nil {
+		return nil, "", fmt.Errorf("error al serializar los datos a MessagePack: %w: %w", ErrSerialization, err)
+	}
+	return buf.Bytes(), ContentTypeMsgpack, nil
+}
+
+// LineProtocolEncoder serializa el reporte en el line protocol de InfluxDB: una línea por colector,
+// con el nombre del colector como measurement, agent_id, agent_name y las etiquetas del reporte
+// como tags y los valores
+// anidados aplanados en campos con rutas separadas por puntos.
+type LineProtocolEncoder struct {
+	CollectorTag bool // Añadir también el nombre del colector como tag "collector"
+}
+
+// Encode implementa la interfaz Encoder
+func (e LineProtocolEncoder) Encode(r *report.AgentReport) ([]byte, string, error) {
+	jsonData, err := json.Marshal(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("error al serializar los datos a JSON: %w: %w", ErrSerialization, err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, "", fmt.Errorf("error al decodificar el reporte: %w: %w", ErrSerialization, err)
+	}
+
+	tags := ",agent_id=" + escapeLineProtocol(r.AgentID, tagEscaper) +
+		",agent_name=" + escapeLineProtocol(r.AgentName, tagEscaper)
+	for _, name := range sortedLabelNames(r.Labels) {
+		tags += "," + escapeLineProtocol(name, tagEscaper) + "=" + escapeLineProtocol(r.Labels[name], tagEscaper)
+	}
+	timestamp := strconv.FormatInt(r.Timestamp*1e9, 10) // Precisión por defecto de InfluxDB: nanosegundos
+
+	sections := make([]string, 0, len(doc))
+	for key := range doc {
+		if strings.HasSuffix(key, "_metrics") {
+			sections = append(sections, key)
+		}
+	}
+	sort.Strings(sections)
+
+	var buf bytes.Buffer
+	for _, key := range sections {
+		fields := make(map[string]string)
+		flattenFields("", doc[key], fields)
+		if len(fields) == 0 {
+			continue // Una línea sin campos no es válida
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		collectorName := strings.TrimSuffix(key, "_metrics")
+		buf.WriteString(escapeLineProtocol(collectorName, measurementEscaper))
+		buf.WriteString(tags)
+		if e.CollectorTag {
+			buf.WriteString(",collector=" + escapeLineProtocol(collectorName, tagEscaper))
+		}
+		for i, name := range names {
+			if i == 0 {
+				buf.WriteByte(' ')
+			} else {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(escapeLineProtocol(name, tagEscaper))
+			buf.WriteByte('=')
+			buf.WriteString(fields[name])
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(timestamp)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), ContentTypeLineProtocol, nil
+}
+
+// sortedLabelNames devuelve los nombres de las etiquetas ordenados, como recomienda InfluxDB para los tags
+func sortedLabelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Escapes del line protocol: los measurements escapan comas y espacios; tags y nombres de campo
+// además el signo igual. Los valores de texto van entre comillas con \ y " escapados.
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+	stringValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	lineBreaks         = strings.NewReplacer("\n", " ", "\r", " ") // El line protocol no admite saltos de línea en ningún valor
+)
+
+// escapeLineProtocol escapa s con el escaper indicado, quitando antes los saltos de línea
+func escapeLineProtocol(s string, escaper *strings.Replacer) string {
+	return escaper.Replace(lineBreaks.Replace(s))
+}
+
+// flattenFields añade a out los valores de v ya formateados como campos del line protocol,
+// con rutas separadas por puntos. Los números se envían siempre como float para que un campo
+// no cambie de tipo entre reportes.
+func flattenFields(path string, v interface{}, out map[string]string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch val := v.(type) {
+	case float64:
+		out[path] = strconv.FormatFloat(val, 'g', -1, 64)
+	case bool:
+		out[path] = strconv.FormatBool(val)
+	case string:
+		out[path] = `"` + escapeLineProtocol(val, stringValueEscaper) + `"`
+	case map[string]interface{}:
+		for k, nested := range val {
+			flattenFields(join(k), nested, out)
+		}
+	case []interface{}:
+		for i, nested := range val {
+			flattenFields(join(strconv.Itoa(i)), nested, out)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atrox39/logtick/config"
+	"github.com/atrox39/logtick/report"
+)
+
+// graphiteDialTimeout limita el establecimiento de la conexión TCP
+const graphiteDialTimeout = 5 * time.Second
+
+// graphiteWriteTimeout limita la escritura de cada reporte
+const graphiteWriteTimeout = 10 * time.Second
+
+// graphiteUnsafe son los caracteres no válidos dentro de un componente de ruta de Graphite
+var graphiteUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// graphiteSkipped son los campos del reporte que identifican al agente y no son métricas
+var graphiteSkipped = map[string]bool{"agent_id": true, "agent_name": true, "report_id": true, "labels": true, "timestamp": true, "sequence": true}
+
+// GraphiteSender envía los reportes en el formato plaintext de Graphite
+// ("ruta.de.la.metrica valor timestamp") sobre una conexión TCP persistente.
+type GraphiteSender struct {
+	address string
+	prefix  string
+
+	mu   sync.Mutex // Protege conn y serializa las escrituras
+	conn net.Conn   // nil mientras no haya conexión; se restablece en el siguiente envío
+}
+
+// NewGraphiteSender crea una nueva instancia de GraphiteSender.
+// La conexión se establece en el primer envío.
+func NewGraphiteSender(cfg *config.GraphiteConfig) (*GraphiteSender, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, fmt.Errorf("graphite.address no puede estar vacío")
+	}
+	return &GraphiteSender{
+		address: cfg.Address,
+		prefix:  strings.Trim(cfg.Prefix, "."),
+	}, nil
+}
+
+// Send aplana el reporte en rutas con puntos y las escribe en la conexión.
+// Si la escritura falla la conexión se descarta para reconectar en el siguiente envío.
+// Implementa la interfaz Sink.
+func (s *GraphiteSender) Send(ctx context.Context, r *report.AgentReport) error {
+	lines, err := s.format(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		dialer := net.Dialer{Timeout: graphiteDialTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", s.address)
+		if err != nil {
+			return fmt.Errorf("error al conectar con Graphite '%s': %w", s.address, err)
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(graphiteWriteTimeout))
+	if _, err := s.conn.Write(lines); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("error al escribir en Graphite: %w", err)
+	}
+	return nil
+}
+
+// format convierte el reporte en líneas plaintext ordenadas por ruta
+func (s *GraphiteSender) format(r *report.AgentReport) ([]byte, error) {
+	jsonData, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar los datos a JSON: %w: %w", ErrSerialization, err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("error al decodificar el reporte: %w", err)
+	}
+
+	base := graphiteUnsafe.ReplaceAllString(r.AgentName, "_")
+	if s.prefix != "" {
+		base = s.prefix + "." + base
+	}
+
+	values := make(map[string]float64)
+	for key, section := range doc {
+		if graphiteSkipped[key] {
+			continue
+		}
+		flattenGraphite(base+"."+graphiteUnsafe.ReplaceAllString(strings.TrimSuffix(key, "_metrics"), "_"), section, values)
+	}
+
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "%s %v %d\n", path, values[path], r.Timestamp)
+	}
+	return buf.Bytes(), nil
+}
+
+// flattenGraphite añade a out los valores numéricos de v (los booleanos como 0/1), con rutas separadas por puntos
+func flattenGraphite(path string, v interface{}, out map[string]float64) {
+	switch val := v.(type) {
+	case float64:
+		out[path] = val
+	case bool:
+		if val {
+			out[path] = 1
+		} else {
+			out[path] = 0
+		}
+	case map[string]interface{}:
+		for k, nested := range val {
+			flattenGraphite(path+"."+graphiteUnsafe.ReplaceAllString(k, "_"), nested, out)
+		}
+	case []interface{}:
+		for i, nested := range val {
+			flattenGraphite(fmt.Sprintf("%s.%d", path, i), nested, out)
+		}
+	}
+}
+
+// Close cierra la conexión TCP si está abierta.
+// Implementa la interfaz Sink.
+func (s *GraphiteSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -2,38 +2,239 @@ package sender
 
 import (
 	"bytes"
-	"encoding/json"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/config"
+	"github.com/atrox39/logtick/report"
 )
 
+// InFlightSends indica cuántos envíos HTTP al backend están en curso.
+// Se registra en Prometheus desde main.
+var InFlightSends = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "agent_sender_in_flight_requests",
+	Help: "Number of report sends to the backend currently in flight.",
+})
+
 // HTTPSender es una interfaz para enviar datos via HTTP
 type HTTPSender struct {
-	client *http.Client
-	url    string
+	client   *http.Client
+	urls     []string // Endpoints en orden de preferencia; se prueban hasta que uno acepte
+	method   string
+	path     string        // Plantilla de ruta/query añadida a url
+	inFlight chan struct{} // Semáforo que limita los envíos simultáneos (nil = sin límite)
+	encoder  Encoder       // Formato del cuerpo (JSON por defecto)
+	delta    *deltaEncoder // Modo delta (nil = reportes siempre completos); solo con JSON
+	compress bool          // Comprimir el cuerpo con gzip
+
+	// Autenticación; bearerToken tiene prioridad sobre username/password
+	bearerToken string
+	username    string
+	password    string
+
+	preferredMu sync.Mutex
+	preferred   int // Índice del último endpoint que aceptó un reporte; se prueba primero
+}
+
+// NewHTTPSender crea una nueva instancia de HTTPSender que serializa los reportes con encoder
+// (JSON si es nil). Con varias URLs, cada envío se intenta en orden empezando por la última que
+// aceptó un reporte, hasta que alguna lo acepte.
+func NewHTTPSender(targetURLs []string, cfg *config.SenderConfig, encoder Encoder) (*HTTPSender, error) {
+	if cfg == nil {
+		cfg = &config.SenderConfig{}
+	}
+	if len(targetURLs) == 0 {
+		return nil, fmt.Errorf("no se configuró ninguna URL de destino")
+	}
+	if encoder == nil {
+		encoder = JSONEncoder{}
+	}
+	if _, isJSON := encoder.(JSONEncoder); cfg.DeltaMode && !isJSON {
+		return nil, fmt.Errorf("el modo delta solo es compatible con el formato json")
+	}
+
+	method := "POST"
+	if cfg.Method != "" {
+		method = strings.ToUpper(cfg.Method)
+	}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("método HTTP no soportado para el envío: %s", method)
+	}
+
+	// Limitar las conexiones al backend para no saturarlo ni agotar sockets locales en ráfagas
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLS != nil {
+		var err error
+		if tlsConfig, err = cfg.TLS.Build("sender.tls"); err != nil {
+			return nil, err
+		}
+	}
+	tlsConfig.VerifyConnection = logTLSHandshake(logrus.WithField("sender", "http"))
+	transport.TLSClientConfig = tlsConfig
+
+	s := &HTTPSender{
+		client:   &http.Client{Timeout: 10 * time.Second, Transport: transport}, // Timeout para evitar bloqueos
+		urls:     targetURLs,
+		method:   method,
+		path:     cfg.Path,
+		compress: cfg.Compress,
+		encoder:  encoder,
+	}
+	if cfg.Auth != nil {
+		s.bearerToken, s.username, s.password = cfg.Auth.BearerToken, cfg.Auth.Username, cfg.Auth.Password
+	}
+	if cfg.MaxInFlight > 0 {
+		s.inFlight = make(chan struct{}, cfg.MaxInFlight)
+	}
+	if cfg.DeltaMode {
+		s.delta = newDeltaEncoder(time.Duration(cfg.DeltaFullEverySeconds) * time.Second)
+	}
+	return s, nil
+}
+
+// logTLSHandshake devuelve un VerifyConnection que registra en debug la versión y el cifrado
+// negociados en cada conexión, para diagnosticar problemas de TLS con el backend
+func logTLSHandshake(log *logrus.Entry) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		log.WithFields(logrus.Fields{
+			"server_name":  cs.ServerName,
+			"tls_version":  tls.VersionName(cs.Version),
+			"cipher_suite": tls.CipherSuiteName(cs.CipherSuite),
+			"resumed":      cs.DidResume,
+		}).Debug("Conexión TLS establecida.")
+		return nil
+	}
+}
+
+// requestURL construye la URL final de un endpoint sustituyendo los marcadores de la ruta con datos del reporte
+func (s *HTTPSender) requestURL(baseURL string, r *report.AgentReport) string {
+	if s.path == "" {
+		return baseURL
+	}
+	replacer := strings.NewReplacer(
+		"{agent_id}", url.PathEscape(r.AgentID),
+		"{agent_name}", url.PathEscape(r.AgentName),
+		"{timestamp}", strconv.FormatInt(r.Timestamp, 10),
+		"{sequence}", strconv.FormatUint(r.Sequence, 10),
+	)
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(replacer.Replace(s.path), "/")
 }
 
-// NewHTTPSender crea una nueva instancia de HTTPSender
-func NewHTTPSender(url string) *HTTPSender {
-	return &HTTPSender{
-		client: &http.Client{Timeout: 10 * time.Second}, // Timeout para evitar bloqueos
-		url:    url,
+// endpointOrder devuelve los índices de los endpoints empezando por el preferido
+func (s *HTTPSender) endpointOrder() []int {
+	s.preferredMu.Lock()
+	first := s.preferred
+	s.preferredMu.Unlock()
+
+	order := make([]int, len(s.urls))
+	for i := range order {
+		order[i] = (first + i) % len(s.urls)
 	}
+	return order
 }
 
-// Send envía los datos en formato JSON a la URL configurada
-func (s *HTTPSender) Send(data interface{}) error {
-	jsonData, err := json.Marshal(data)
+// Send serializa el reporte con el formato configurado y lo envía con el método configurado al
+// primer endpoint que lo acepte. En modo delta solo se envían los campos que cambiaron desde el
+// último reporte aceptado. Implementa la interfaz Sink.
+func (s *HTTPSender) Send(ctx context.Context, r *report.AgentReport) error {
+	data, contentType, err := s.encoder.Encode(r)
 	if err != nil {
-		return fmt.Errorf("error al serializar los datos a JSON: %w", err)
+		return err
+	}
+
+	accepted := func() {}
+	if s.delta != nil {
+		s.delta.mu.Lock()
+		defer s.delta.mu.Unlock()
+		if data, accepted, err = s.delta.encode(data); err != nil {
+			return err
+		}
+	}
+
+	body := data
+	if s.compress {
+		if body, err = gzipBytes(data); err != nil {
+			return err
+		}
+	}
+
+	// Esperar un hueco en el semáforo respetando la cancelación del contexto
+	if s.inFlight != nil {
+		select {
+		case s.inFlight <- struct{}{}:
+			defer func() { <-s.inFlight }()
+		case <-ctx.Done():
+			return fmt.Errorf("envío cancelado esperando un hueco libre: %w", ctx.Err())
+		}
 	}
+	InFlightSends.Inc()
+	defer InFlightSends.Dec()
 
-	req, err := http.NewRequest("POST", s.url, bytes.NewBuffer(jsonData))
+	var errs []string
+	for _, i := range s.endpointOrder() {
+		endpoint := s.requestURL(s.urls[i], r)
+		err := s.post(ctx, endpoint, body, contentType, r.ReportID)
+		if err == nil {
+			accepted()
+			s.preferredMu.Lock()
+			s.preferred = i
+			s.preferredMu.Unlock()
+			logrus.WithField("endpoint", s.urls[i]).Debug("Reporte aceptado por el endpoint.")
+			return nil // Éxito
+		}
+		if ctx.Err() != nil {
+			return err // Cancelado: no tiene sentido probar el resto
+		}
+		if len(s.urls) == 1 {
+			return err
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", s.urls[i], err))
+	}
+	return fmt.Errorf("ningún endpoint aceptó el reporte: %s", strings.Join(errs, "; "))
+}
+
+// post envía un cuerpo ya serializado a un endpoint concreto. reportID se envía como X-Request-ID e
+// Idempotency-Key; al ser el del reporte, los reintentos y la conmutación entre endpoints repiten
+// la misma clave y el backend puede descartar los duplicados.
+func (s *HTTPSender) post(ctx context.Context, endpoint string, body []byte, contentType, reportID string) error {
+	req, err := http.NewRequestWithContext(ctx, s.method, endpoint, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("error al crear la solicitud HTTP: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	if reportID != "" {
+		req.Header.Set("X-Request-ID", reportID)
+		req.Header.Set("Idempotency-Key", reportID)
+	}
+	if s.compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	switch {
+	case s.bearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+s.bearerToken)
+	case s.username != "":
+		req.SetBasicAuth(s.username, s.password)
+	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -41,9 +242,28 @@ func (s *HTTPSender) Send(data interface{}) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		return nil // Éxito
-	} else {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return fmt.Errorf("el servidor respondió con el estado %d: %s", resp.StatusCode, resp.Status)
 	}
+	return nil
+}
+
+// gzipBytes comprime un cuerpo con gzip
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, fmt.Errorf("error al comprimir el reporte: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("error al comprimir el reporte: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Close libera las conexiones inactivas del cliente HTTP.
+// Implementa la interfaz Sink.
+func (s *HTTPSender) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
 }
@@ -0,0 +1,82 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/atrox39/logtick/config"
+	"github.com/atrox39/logtick/report"
+)
+
+// InfluxSender escribe los reportes en la API v2 de InfluxDB (/api/v2/write) como line protocol,
+// con una línea por colector etiquetada con agent_id, agent_name y collector.
+type InfluxSender struct {
+	client   *http.Client
+	writeURL string // URL de escritura con org, bucket y precisión ya incluidos
+	token    string
+	encoder  LineProtocolEncoder
+}
+
+// NewInfluxSender crea una nueva instancia de InfluxSender
+func NewInfluxSender(cfg *config.InfluxConfig) (*InfluxSender, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("influx.url no puede estar vacío")
+	}
+	query := url.Values{}
+	query.Set("org", cfg.Org)
+	query.Set("bucket", cfg.Bucket)
+	query.Set("precision", "ns")
+	return &InfluxSender{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		writeURL: strings.TrimRight(cfg.URL, "/") + "/api/v2/write?" + query.Encode(),
+		token:    cfg.Token,
+		encoder:  LineProtocolEncoder{CollectorTag: true},
+	}, nil
+}
+
+// Send escribe el reporte en InfluxDB.
+// Implementa la interfaz Sink.
+func (s *InfluxSender) Send(ctx context.Context, r *report.AgentReport) error {
+	body, contentType, err := s.encoder.Encode(r)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil // Ningún colector aportó campos
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.writeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error al crear la solicitud a InfluxDB: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error al escribir en InfluxDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// InfluxDB explica el motivo del rechazo (ej. un campo con otro tipo) en el cuerpo
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("InfluxDB respondió con el estado %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// Close libera las conexiones inactivas del cliente HTTP.
+// Implementa la interfaz Sink.
+func (s *InfluxSender) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
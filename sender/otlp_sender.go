@@ -0,0 +1,342 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/atrox39/logtick/collector"
+	"github.com/atrox39/logtick/config"
+	"github.com/atrox39/logtick/promexport"
+	"github.com/atrox39/logtick/report"
+)
+
+// otlpTimeout limita cada exportación, igual que el cliente HTTP del resto de enviadores
+const otlpTimeout = 10 * time.Second
+
+// MetadataFunc devuelve los metadatos publicados por un colector activo (nil si no publica)
+type MetadataFunc func(collectorName string) []collector.MetricDescriptor
+
+// OTLPSender exporta los reportes como métricas OTLP a un colector de OpenTelemetry, por gRPC
+// o por HTTP (protobuf). Los nombres y atributos de las series coinciden con los del puente de
+// /metrics; el tipo de instrumento y la unidad se toman de los metadatos de cada colector.
+type OTLPSender struct {
+	protocol string
+	endpoint string // URL de /v1/metrics (http) o host:puerto (grpc)
+	headers  map[string]string
+	hostname string
+	metadata MetadataFunc
+	start    uint64 // Inicio de las sumas acumuladas (arranque del agente), en nanosegundos
+
+	client *http.Client     // Solo con protocol http
+	conn   *grpc.ClientConn // Solo con protocol grpc
+	rpc    colmetricspb.MetricsServiceClient
+	log    *logrus.Entry
+}
+
+// NewOTLPSender crea una nueva instancia de OTLPSender.
+// metadata puede ser nil: entonces todas las métricas se exportan como gauges.
+func NewOTLPSender(cfg *config.OTLPConfig, metadata MetadataFunc) (*OTLPSender, error) {
+	if cfg == nil || cfg.Endpoint == "" {
+		return nil, fmt.Errorf("otlp.endpoint no puede estar vacío")
+	}
+	hostname, _ := os.Hostname()
+
+	s := &OTLPSender{
+		protocol: cfg.Protocol,
+		headers:  cfg.Headers,
+		hostname: hostname,
+		metadata: metadata,
+		start:    uint64(time.Now().UnixNano()),
+		log:      logrus.WithField("sender", "otlp"),
+	}
+
+	switch cfg.Protocol {
+	case "grpc":
+		creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		if cfg.Insecure {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.NewClient(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("error al crear el cliente gRPC de OTLP: %w", err)
+		}
+		s.endpoint = cfg.Endpoint
+		s.conn = conn
+		s.rpc = colmetricspb.NewMetricsServiceClient(conn)
+	case "", "http":
+		endpoint, err := otlpHTTPEndpoint(cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		s.protocol = "http"
+		s.endpoint = endpoint
+		s.client = &http.Client{Timeout: otlpTimeout}
+	default:
+		return nil, fmt.Errorf("otlp.protocol no soportado: %s", cfg.Protocol)
+	}
+	return s, nil
+}
+
+// otlpHTTPEndpoint añade la ruta estándar /v1/metrics a un endpoint sin ruta
+func otlpHTTPEndpoint(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("otlp.endpoint inválido: %w", err)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/metrics"
+	}
+	return u.String(), nil
+}
+
+// Send exporta todas las secciones del reporte.
+// Implementa la interfaz Sink.
+func (s *OTLPSender) Send(ctx context.Context, r *report.AgentReport) error {
+	req := s.buildRequest(r)
+	if len(req.ResourceMetrics[0].ScopeMetrics) == 0 {
+		return nil // Ningún colector aportó valores
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, otlpTimeout)
+	defer cancel()
+
+	var resp *colmetricspb.ExportMetricsServiceResponse
+	var err error
+	if s.protocol == "grpc" {
+		resp, err = s.exportGRPC(ctx, req)
+	} else {
+		resp, err = s.exportHTTP(ctx, req)
+	}
+	if err != nil {
+		return err
+	}
+	// Un rechazo parcial no se reintenta: reenviar el reporte no cambiaría el resultado
+	if partial := resp.GetPartialSuccess(); partial.GetRejectedDataPoints() > 0 {
+		s.log.WithFields(logrus.Fields{
+			"rejected_data_points": partial.GetRejectedDataPoints(),
+			"message":              partial.GetErrorMessage(),
+		}).Warn("El colector OTLP rechazó parte de las métricas.")
+	}
+	return nil
+}
+
+// exportGRPC envía la solicitud con MetricsService/Export; las cabeceras viajan como metadata
+func (s *OTLPSender) exportGRPC(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	if len(s.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(s.headers))
+	}
+	resp, err := s.rpc.Export(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error al exportar a OTLP por gRPC: %w", err)
+	}
+	return resp, nil
+}
+
+// exportHTTP envía la solicitud como protobuf a /v1/metrics
+func (s *OTLPSender) exportHTTP(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar las métricas OTLP: %w: %w", ErrSerialization, err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error al crear la solicitud OTLP: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	for name, value := range s.headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error al exportar a OTLP por HTTP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("el colector OTLP respondió con el estado %d: %s", resp.StatusCode, strings.TrimSpace(string(truncate(data, 512))))
+	}
+	out := &colmetricspb.ExportMetricsServiceResponse{}
+	_ = proto.Unmarshal(data, out) // La respuesta solo informa de rechazos parciales; un cuerpo vacío es válido
+	return out, nil
+}
+
+// truncate limita data a n bytes
+func truncate(data []byte, n int) []byte {
+	if len(data) > n {
+		return data[:n]
+	}
+	return data
+}
+
+// buildRequest convierte el reporte en una solicitud con un recurso (el agente) y un ámbito por colector
+func (s *OTLPSender) buildRequest(r *report.AgentReport) *colmetricspb.ExportMetricsServiceRequest {
+	hostname := s.hostname
+	if r.Host != nil {
+		hostname = r.Host.Hostname // Incluye la sustitución configurada en hostname
+	}
+	resource := &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+		stringAttribute("service.name", "logtick-agent"),
+		stringAttribute("agent_id", r.AgentID),
+		stringAttribute("agent_name", r.AgentName),
+		stringAttribute("host.name", hostname),
+	}}
+	if r.Host != nil {
+		resource.Attributes = append(resource.Attributes, stringAttribute("os.type", r.Host.OS))
+	}
+	for _, name := range sortedLabelNames(r.Labels) {
+		resource.Attributes = append(resource.Attributes, stringAttribute(name, r.Labels[name]))
+	}
+	if r.AgentVersion != "" {
+		resource.Attributes = append(resource.Attributes, stringAttribute("service.version", r.AgentVersion))
+	}
+
+	sections := r.Sections()
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var scopes []*metricspb.ScopeMetrics
+	for _, name := range names {
+		collectedAt := r.Timestamp
+		if ts, ok := r.CollectedAt[name]; ok {
+			collectedAt = ts
+		}
+		metrics := otlpMetrics(promexport.Flatten(name, sections[name]), s.descriptors(name), s.start, uint64(collectedAt)*uint64(time.Second))
+		if len(metrics) == 0 {
+			continue
+		}
+		scopes = append(scopes, &metricspb.ScopeMetrics{
+			Scope:   &commonpb.InstrumentationScope{Name: "logtick/" + name, Version: r.AgentVersion},
+			Metrics: metrics,
+		})
+	}
+
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{Resource: resource, ScopeMetrics: scopes}},
+	}
+}
+
+// descriptors indexa por nombre de campo los metadatos publicados por un colector
+func (s *OTLPSender) descriptors(collectorName string) map[string]collector.MetricDescriptor {
+	if s.metadata == nil {
+		return nil
+	}
+	out := make(map[string]collector.MetricDescriptor)
+	for _, d := range s.metadata(collectorName) {
+		out[d.Name] = d
+	}
+	return out
+}
+
+// otlpMetrics agrupa los valores aplanados de un colector por nombre de métrica. Los campos
+// declarados como collector.Counter se exportan como sumas monótonas acumuladas desde start;
+// el resto, incluidos los campos sin metadatos, como gauges.
+func otlpMetrics(samples []promexport.Sample, descriptors map[string]collector.MetricDescriptor, start, timestamp uint64) []*metricspb.Metric {
+	byName := make(map[string]*metricspb.Metric)
+	var metrics []*metricspb.Metric
+	for _, sample := range samples {
+		point := &metricspb.NumberDataPoint{
+			TimeUnixNano: timestamp,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: sample.Value},
+			Attributes:   labelAttributes(sample.Labels),
+		}
+
+		metric, ok := byName[sample.Name]
+		if !ok {
+			descriptor := descriptors[sample.Field]
+			metric = &metricspb.Metric{
+				Name:        sample.Name,
+				Description: descriptor.Description,
+				Unit:        otlpUnit(descriptor.Unit),
+			}
+			if descriptor.Type == collector.Counter {
+				metric.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+					IsMonotonic:            true,
+				}}
+			} else {
+				metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+			}
+			byName[sample.Name] = metric
+			metrics = append(metrics, metric)
+		}
+
+		switch data := metric.Data.(type) {
+		case *metricspb.Metric_Sum:
+			point.StartTimeUnixNano = start
+			data.Sum.DataPoints = append(data.Sum.DataPoints, point)
+		case *metricspb.Metric_Gauge:
+			data.Gauge.DataPoints = append(data.Gauge.DataPoints, point)
+		}
+	}
+	return metrics
+}
+
+// otlpUnits traduce las unidades de los metadatos a UCUM, como recomienda OpenTelemetry
+var otlpUnits = map[collector.Unit]string{
+	collector.UnitBytes:     "By",
+	collector.UnitKilobytes: "kBy",
+	collector.UnitMegabytes: "MBy",
+	collector.UnitGigabytes: "GBy",
+	collector.UnitPercent:   "%",
+	collector.UnitSeconds:   "s",
+	collector.UnitCelsius:   "Cel",
+	collector.UnitCount:     "1",
+	collector.UnitPerSecond: "1/s",
+}
+
+// otlpUnit devuelve la unidad UCUM de u, o vacío si no tiene
+func otlpUnit(u collector.Unit) string {
+	return otlpUnits[u]
+}
+
+// labelAttributes convierte las etiquetas de una serie en atributos ordenados por nombre
+func labelAttributes(labels map[string]string) []*commonpb.KeyValue {
+	if len(labels) == 0 {
+		return nil
+	}
+	attrs := make([]*commonpb.KeyValue, 0, len(labels))
+	for name, value := range labels {
+		attrs = append(attrs, stringAttribute(name, value))
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+// Close cierra la conexión gRPC o libera las conexiones inactivas del cliente HTTP.
+// Implementa la interfaz Sink.
+func (s *OTLPSender) Close() error {
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	s.client.CloseIdleConnections()
+	return nil
+}
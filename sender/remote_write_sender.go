@@ -0,0 +1,120 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/atrox39/logtick/promexport"
+	"github.com/atrox39/logtick/report"
+)
+
+// RemoteWriteSender envía los reportes con el protocolo remote-write 1.0 de Prometheus
+// (WriteRequest protobuf comprimido con snappy). Los nombres y etiquetas de las series
+// coinciden con los que expone el puente de /metrics.
+type RemoteWriteSender struct {
+	client *http.Client
+	url    string
+}
+
+// NewRemoteWriteSender crea una nueva instancia de RemoteWriteSender
+func NewRemoteWriteSender(url string) (*RemoteWriteSender, error) {
+	if url == "" {
+		return nil, fmt.Errorf("la URL de remote-write no puede estar vacía")
+	}
+	return &RemoteWriteSender{
+		client: &http.Client{Timeout: 10 * time.Second},
+		url:    url,
+	}, nil
+}
+
+// label es un par nombre/valor de una serie
+type label struct {
+	name, value string
+}
+
+// Send codifica todas las secciones del reporte como series con una única muestra.
+// Implementa la interfaz Sink.
+func (s *RemoteWriteSender) Send(ctx context.Context, r *report.AgentReport) error {
+	timestampMs := r.Timestamp * 1000
+
+	var body []byte
+	for collectorName, data := range r.Sections() {
+		for _, sample := range promexport.Flatten(collectorName, data) {
+			labels := []label{
+				{"__name__", sample.Name},
+				{"agent_name", r.AgentName},
+				{"agent_id", r.AgentID},
+			}
+			for name, value := range r.Labels {
+				labels = append(labels, label{name, value})
+			}
+			for name, value := range sample.Labels {
+				labels = append(labels, label{name, value})
+			}
+			sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+
+			body = protowire.AppendTag(body, 1, protowire.BytesType) // WriteRequest.timeseries
+			body = protowire.AppendBytes(body, encodeTimeSeries(labels, sample.Value, timestampMs))
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(snappy.Encode(nil, body)))
+	if err != nil {
+		return fmt.Errorf("error al crear la solicitud de remote-write: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error al enviar la solicitud de remote-write: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("el servidor de remote-write respondió con el estado %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+}
+
+// encodeTimeSeries codifica un TimeSeries con sus etiquetas y una muestra
+func encodeTimeSeries(labels []label, value float64, timestampMs int64) []byte {
+	var ts []byte
+	for _, l := range labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType) // Label.name
+		lb = protowire.AppendString(lb, l.name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType) // Label.value
+		lb = protowire.AppendString(lb, l.value)
+
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType) // TimeSeries.labels
+		ts = protowire.AppendBytes(ts, lb)
+	}
+
+	var sb []byte
+	sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type) // Sample.value
+	sb = protowire.AppendFixed64(sb, math.Float64bits(value))
+	sb = protowire.AppendTag(sb, 2, protowire.VarintType) // Sample.timestamp
+	sb = protowire.AppendVarint(sb, uint64(timestampMs))
+
+	ts = protowire.AppendTag(ts, 2, protowire.BytesType) // TimeSeries.samples
+	return protowire.AppendBytes(ts, sb)
+}
+
+// Close libera las conexiones inactivas del cliente HTTP.
+// Implementa la interfaz Sink.
+func (s *RemoteWriteSender) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
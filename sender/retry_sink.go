@@ -0,0 +1,150 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/report"
+)
+
+// ErrBuffered indica que el reporte no se envió por falta de presupuesto y quedó en el búfer
+var ErrBuffered = errors.New("presupuesto de envío agotado, reporte almacenado en el búfer")
+
+// BufferedReports indica cuántos reportes esperan en los búferes de todos los destinos.
+// Se registra en Prometheus desde main.
+var BufferedReports = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "agent_sender_buffered_reports",
+	Help: "Number of reports buffered waiting for send budget.",
+})
+
+// RetrySink reintenta los envíos fallidos de otro Sink consumiendo un presupuesto compartido.
+// Cuando el presupuesto se agota o se acaban los reintentos, los reportes se almacenan en memoria
+// (los más antiguos se descartan al llenarse) y se reenvían en orden en las siguientes llamadas a Send.
+type RetrySink struct {
+	next       Sink
+	budget     *Budget
+	maxRetries int
+	backoff    time.Duration
+	maxBuffer  int
+
+	mu     sync.Mutex
+	buffer []*report.AgentReport
+}
+
+// NewRetrySink envuelve next con reintentos limitados por budget
+func NewRetrySink(next Sink, budget *Budget, maxRetries int, backoff time.Duration, maxBuffer int) *RetrySink {
+	return &RetrySink{
+		next:       next,
+		budget:     budget,
+		maxRetries: maxRetries,
+		backoff:    backoff,
+		maxBuffer:  maxBuffer,
+	}
+}
+
+// Send reenvía primero los reportes pendientes y después envía r con reintentos.
+// Devuelve ErrBuffered si r quedó pendiente por falta de presupuesto.
+func (s *RetrySink) Send(ctx context.Context, r *report.AgentReport) error {
+	s.flush(ctx)
+
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(s.backoff * time.Duration(attempt)):
+			case <-ctx.Done():
+				return fmt.Errorf("reintento cancelado: %w", ctx.Err())
+			}
+		}
+		if !s.budget.Allow() {
+			s.push(r)
+			return ErrBuffered
+		}
+		if lastErr = s.next.Send(ctx, r); lastErr == nil {
+			return nil
+		}
+		if errors.Is(lastErr, ErrSerialization) {
+			return lastErr // Ni reintentar ni almacenar: fallaría siempre igual
+		}
+	}
+	s.push(r)
+	return fmt.Errorf("envío fallido tras %d intentos, reporte almacenado en el búfer: %w", s.maxRetries+1, lastErr)
+}
+
+// flush reenvía los reportes pendientes en orden mientras haya presupuesto y el backend responda
+func (s *RetrySink) flush(ctx context.Context) {
+	for s.pending() > 0 && s.budget.Allow() {
+		r := s.pop()
+		if r == nil {
+			return // Otro colector vació el búfer mientras tanto
+		}
+		if err := s.next.Send(ctx, r); err != nil {
+			logrus.WithError(err).Debug("No se pudo reenviar un reporte pendiente. Se conserva en el búfer.")
+			s.requeue(r)
+			return
+		}
+	}
+}
+
+// push añade un reporte al final del búfer descartando el más antiguo si está lleno
+func (s *RetrySink) push(r *report.AgentReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxBuffer <= 0 {
+		return
+	}
+	if len(s.buffer) >= s.maxBuffer {
+		logrus.Warn("Búfer de envío lleno. Se descarta el reporte pendiente más antiguo.")
+		s.buffer = s.buffer[1:]
+		BufferedReports.Dec()
+	}
+	s.buffer = append(s.buffer, r)
+	BufferedReports.Inc()
+}
+
+// requeue devuelve un reporte al principio del búfer tras un reenvío fallido
+func (s *RetrySink) requeue(r *report.AgentReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buffer) >= s.maxBuffer {
+		return // El búfer se llenó con reportes más recientes
+	}
+	s.buffer = append([]*report.AgentReport{r}, s.buffer...)
+	BufferedReports.Inc()
+}
+
+// pending devuelve el número de reportes en el búfer
+func (s *RetrySink) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buffer)
+}
+
+// pop retira y devuelve el reporte pendiente más antiguo
+func (s *RetrySink) pop() *report.AgentReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buffer) == 0 {
+		return nil
+	}
+	r := s.buffer[0]
+	s.buffer = s.buffer[1:]
+	BufferedReports.Dec()
+	return r
+}
+
+// Close cierra el destino envuelto. Los reportes aún pendientes se pierden.
+func (s *RetrySink) Close() error {
+	s.mu.Lock()
+	if n := len(s.buffer); n > 0 {
+		logrus.Warnf("Se descartan %d reportes pendientes al cerrar el enviador.", n)
+	}
+	s.mu.Unlock()
+	return s.next.Close()
+}
@@ -0,0 +1,87 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/atrox39/logtick/report"
+)
+
+// Route asocia un conjunto de colectores a un destino
+type Route struct {
+	Name       string
+	Collectors []string
+	Sink       Sink
+}
+
+// Router reparte cada reporte entre varios destinos según el colector de cada sección.
+// Cada ruta recibe solo las secciones de sus colectores; el destino por defecto (si existe)
+// recibe las secciones de los colectores que no están asignados a ninguna ruta.
+type Router struct {
+	routes   []Route
+	fallback Sink // nil = las secciones sin ruta no se envían
+	bound    map[string]bool
+}
+
+// NewRouter crea un enrutador con las rutas indicadas y un destino por defecto opcional
+func NewRouter(routes []Route, fallback Sink) *Router {
+	bound := make(map[string]bool)
+	for _, route := range routes {
+		for _, name := range route.Collectors {
+			bound[name] = true
+		}
+	}
+	return &Router{routes: routes, fallback: fallback, bound: bound}
+}
+
+// Send envía a cada destino las secciones que le corresponden, omitiendo los que no tienen ninguna.
+// Un fallo en un destino no impide el envío al resto; los errores se combinan.
+func (rt *Router) Send(ctx context.Context, r *report.AgentReport) error {
+	var errs []error
+	for _, route := range rt.routes {
+		collectors := route.Collectors
+		sub := r.Filter(func(name string) bool { return contains(collectors, name) })
+		if len(sub.Sections()) == 0 {
+			continue
+		}
+		if err := route.Sink.Send(ctx, sub); err != nil {
+			errs = append(errs, fmt.Errorf("destino '%s': %w", route.Name, err))
+		}
+	}
+
+	if rt.fallback != nil {
+		sub := r.Filter(func(name string) bool { return !rt.bound[name] })
+		if len(sub.Sections()) > 0 {
+			if err := rt.fallback.Send(ctx, sub); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close cierra todos los destinos
+func (rt *Router) Close() error {
+	var errs []error
+	for _, route := range rt.routes {
+		if err := route.Sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if rt.fallback != nil {
+		if err := rt.fallback.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
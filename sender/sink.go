@@ -0,0 +1,80 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/atrox39/logtick/config"
+	"github.com/atrox39/logtick/report"
+)
+
+// ErrSerialization indica que el reporte no pudo serializarse. Reintentarlo no sirve de nada:
+// el fallo está en los datos de algún colector, no en la red.
+var ErrSerialization = errors.New("reporte no serializable")
+
+// Sink es un destino de reportes del agente. Los enviadores concretos (HTTP, etc.)
+// la implementan para que main no dependa de ningún tipo en particular.
+type Sink interface {
+	Send(ctx context.Context, r *report.AgentReport) error
+	Close() error
+}
+
+// MultiSink reenvía cada reporte a todos sus destinos.
+// Un fallo en un destino no impide el envío al resto; los errores se combinan.
+type MultiSink []Sink
+
+// Send envía el reporte a todos los destinos
+func (m MultiSink) Send(ctx context.Context, r *report.AgentReport) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Send(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close cierra todos los destinos
+func (m MultiSink) Close() error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verificación en tiempo de compilación
+var (
+	_ Sink = (*HTTPSender)(nil)
+	_ Sink = MultiSink(nil)
+	_ Sink = (*RetrySink)(nil)
+	_ Sink = (*GraphiteSender)(nil)
+	_ Sink = (*RemoteWriteSender)(nil)
+	_ Sink = (*Router)(nil)
+	_ Sink = (*SpoolSink)(nil)
+	_ Sink = (*StdoutSink)(nil)
+	_ Sink = (*InfluxSender)(nil)
+	_ Sink = (*OTLPSender)(nil)
+)
+
+// NewSinkFromConfig crea el destino de una ruta según su formato.
+// Los destinos HTTP (json, msgpack, line_protocol) comparten las opciones de la sección sender.
+func NewSinkFromConfig(sc *config.SinkConfig, senderCfg *config.SenderConfig) (Sink, error) {
+	switch sc.Format {
+	case "json", "msgpack", "line_protocol":
+		encoder, err := NewEncoder(sc.Format)
+		if err != nil {
+			return nil, err
+		}
+		return NewHTTPSender([]string{sc.URL}, senderCfg, encoder)
+	case "graphite":
+		return NewGraphiteSender(&config.GraphiteConfig{Address: sc.Address, Prefix: sc.Prefix})
+	case "remote_write":
+		return NewRemoteWriteSender(sc.URL)
+	default:
+		return nil, fmt.Errorf("formato de destino no soportado: %s", sc.Format)
+	}
+}
@@ -0,0 +1,230 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/atrox39/logtick/report"
+)
+
+// Ritmo de reenvío del spool: cada spoolReplayInterval se reenvían como mucho spoolReplayBatch
+// reportes, para no saturar al backend cuando se recupera tras una caída larga
+const (
+	spoolReplayInterval = 5 * time.Second
+	spoolReplayBatch    = 10
+)
+
+// SpooledReports indica cuántos reportes esperan en el spool en disco.
+// Se registra en Prometheus desde main.
+var SpooledReports = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "agent_sender_spooled_reports",
+	Help: "Number of failed reports stored in the disk spool waiting to be replayed.",
+})
+
+// spoolFile es un reporte almacenado en el spool
+type spoolFile struct {
+	name string
+	size int64
+}
+
+// SpoolSink guarda en disco, como archivos JSON, los reportes que otro Sink no pudo enviar y los
+// reenvía en orden en segundo plano cuando el backend vuelve a aceptarlos. Al superar maxBytes se
+// descartan los más antiguos. Los archivos sobreviven a un reinicio del agente.
+type SpoolSink struct {
+	next     Sink
+	dir      string
+	maxBytes int64
+	log      *logrus.Entry
+
+	mu     sync.Mutex
+	files  []spoolFile // Ordenados del más antiguo al más reciente
+	total  int64       // Tamaño total de files en bytes
+	serial uint64      // Desempate para reportes almacenados en el mismo instante
+
+	wake   chan struct{} // Despierta al reenvío tras un envío correcto
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSpoolSink envuelve next con un spool en dir limitado a maxBytes. Los reportes que ya
+// estuvieran en dir se reenvían también. El reenvío se detiene al cancelar ctx o al cerrar el sink.
+func NewSpoolSink(ctx context.Context, next Sink, dir string, maxBytes int64) (*SpoolSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error al crear el directorio de spool %s: %w", dir, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &SpoolSink{
+		next:     next,
+		dir:      dir,
+		maxBytes: maxBytes,
+		log:      logrus.WithField("sender", "spool"),
+		wake:     make(chan struct{}, 1),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("error al leer el directorio de spool %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		s.files = append(s.files, spoolFile{name: e.Name(), size: info.Size()})
+		s.total += info.Size()
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].name < s.files[j].name })
+	SpooledReports.Add(float64(len(s.files)))
+	if len(s.files) > 0 {
+		s.log.WithField("reports", len(s.files)).Info("Reportes pendientes encontrados en el spool. Se reenviarán en segundo plano.")
+	}
+
+	go s.replayLoop(ctx)
+	return s, nil
+}
+
+// Send envía el reporte y, si falla, lo guarda en el spool.
+// Devuelve un error que envuelve ErrBuffered si el reporte quedó almacenado.
+func (s *SpoolSink) Send(ctx context.Context, r *report.AgentReport) error {
+	err := s.next.Send(ctx, r)
+	if err == nil {
+		s.trigger()
+		return nil
+	}
+	if errors.Is(err, ErrSerialization) {
+		return err // Fallaría igual al reenviarlo
+	}
+	if spoolErr := s.store(r); spoolErr != nil {
+		return fmt.Errorf("envío fallido y no se pudo guardar en el spool (%v): %w", spoolErr, err)
+	}
+	return fmt.Errorf("%w: envío fallido, reporte guardado en el spool: %v", ErrBuffered, err)
+}
+
+// store escribe el reporte en un archivo nuevo y descarta los más antiguos si se supera maxBytes
+func (s *SpoolSink) store(r *report.AgentReport) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error al serializar el reporte para el spool: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.serial++
+	name := fmt.Sprintf("%020d-%06d.json", time.Now().UnixNano(), s.serial%1000000)
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("error al escribir el reporte en el spool: %w", err)
+	}
+	s.files = append(s.files, spoolFile{name: name, size: int64(len(data))})
+	s.total += int64(len(data))
+	SpooledReports.Inc()
+
+	for s.total > s.maxBytes && len(s.files) > 1 {
+		oldest := s.files[0]
+		s.log.WithField("file", oldest.name).Warn("Spool lleno. Se descarta el reporte más antiguo.")
+		s.removeLocked(oldest)
+	}
+	return nil
+}
+
+// removeLocked borra el archivo más antiguo del spool. Debe llamarse con mu tomado.
+func (s *SpoolSink) removeLocked(f spoolFile) {
+	if err := os.Remove(filepath.Join(s.dir, f.name)); err != nil && !os.IsNotExist(err) {
+		s.log.WithError(err).WithField("file", f.name).Warn("No se pudo borrar un archivo del spool.")
+	}
+	s.files = s.files[1:]
+	s.total -= f.size
+	SpooledReports.Dec()
+}
+
+// trigger despierta al reenvío sin bloquear
+func (s *SpoolSink) trigger() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// replayLoop reenvía el spool periódicamente y tras cada envío correcto hasta que se cancele ctx
+func (s *SpoolSink) replayLoop(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.replay(ctx)
+	}
+}
+
+// replay reenvía en orden hasta spoolReplayBatch reportes, deteniéndose en el primer fallo
+func (s *SpoolSink) replay(ctx context.Context) {
+	for i := 0; i < spoolReplayBatch && ctx.Err() == nil; i++ {
+		s.mu.Lock()
+		if len(s.files) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		oldest := s.files[0]
+		s.mu.Unlock()
+
+		log := s.log.WithField("file", oldest.name)
+		data, err := os.ReadFile(filepath.Join(s.dir, oldest.name))
+		var r report.AgentReport
+		if err == nil {
+			err = json.Unmarshal(data, &r)
+		}
+		if err != nil {
+			log.WithError(err).Warn("Reporte ilegible en el spool. Se descarta.")
+			s.dropIfOldest(oldest)
+			continue
+		}
+
+		if err := s.next.Send(ctx, &r); err != nil {
+			log.WithError(err).Debug("No se pudo reenviar un reporte del spool. Se conserva.")
+			return
+		}
+		log.Debug("Reporte del spool reenviado.")
+		s.dropIfOldest(oldest)
+	}
+}
+
+// dropIfOldest borra f si sigue siendo el más antiguo (store pudo descartarlo mientras tanto)
+func (s *SpoolSink) dropIfOldest(f spoolFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.files) > 0 && s.files[0].name == f.name {
+		s.removeLocked(f)
+	}
+}
+
+// Close detiene el reenvío y cierra el destino envuelto. Los reportes del spool se conservan
+// en disco para el siguiente arranque.
+func (s *SpoolSink) Close() error {
+	s.cancel()
+	<-s.done
+	return s.next.Close()
+}
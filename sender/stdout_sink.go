@@ -0,0 +1,45 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/atrox39/logtick/report"
+)
+
+// StdoutSink escribe cada reporte como JSON indentado en lugar de enviarlo.
+// Se usa en modo dry-run para validar una configuración sin contactar con ningún backend.
+type StdoutSink struct {
+	mu sync.Mutex // Evita que dos reportes se intercalen en la salida
+	w  io.Writer
+}
+
+// NewStdoutSink crea un destino que escribe los reportes en w (normalmente os.Stdout)
+func NewStdoutSink(w io.Writer) *StdoutSink {
+	return &StdoutSink{w: w}
+}
+
+// Send escribe el reporte tal y como se enviaría.
+// Implementa la interfaz Sink.
+func (s *StdoutSink) Send(ctx context.Context, r *report.AgentReport) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al serializar los datos a JSON: %w: %w", ErrSerialization, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "%s\n", data); err != nil {
+		return fmt.Errorf("error al escribir el reporte: %w", err)
+	}
+	return nil
+}
+
+// Close no libera nada: el escritor pertenece a quien creó el destino.
+// Implementa la interfaz Sink.
+func (s *StdoutSink) Close() error {
+	return nil
+}
@@ -3,6 +3,7 @@ package sender
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"sync"
 	"time"
@@ -19,6 +20,24 @@ type LogMessage struct {
 	Service   string `json:"service"` // e.g., "mysql", "nginx", "system"
 	Message   string `json:"message"` // The actual log line
 	Level     string `json:"level"`   // e.g., "info", "warn", "error"
+
+	RepeatCount int `json:"repeat_count,omitempty"` // Repeticiones suprimidas del mensaje anterior (solo en resúmenes)
+}
+
+// Valores por defecto del keepalive de la conexión de logs
+const (
+	defaultWSPingInterval = 30 * time.Second
+	defaultWSPongTimeout  = 10 * time.Second
+	defaultWSWriteTimeout = 10 * time.Second
+)
+
+// WebSocketLogOptions ajusta el comportamiento de WebSocketLogSender. Los valores cero usan los
+// valores por defecto, salvo DedupWindow, donde 0 desactiva la de-duplicación.
+type WebSocketLogOptions struct {
+	DedupWindow  time.Duration // Ventana en la que se colapsan los mensajes idénticos consecutivos
+	PingInterval time.Duration // Cada cuánto se envía un ping al servidor
+	PongTimeout  time.Duration // Margen tras un ping para recibir el pong antes de dar la conexión por perdida
+	WriteTimeout time.Duration // Tiempo máximo de cada escritura
 }
 
 // WebSocketLogSender gestiona la conexión WebSocket para logs en tiempo real
@@ -30,26 +49,64 @@ type WebSocketLogSender struct {
 	agentID           string
 	agentName         string
 	reconnectInterval time.Duration
+	pingInterval      time.Duration
+	pongTimeout       time.Duration
+	writeTimeout      time.Duration
 	ctx               context.Context
 	cancel            context.CancelFunc
+
+	// De-duplicación de mensajes consecutivos idénticos (protegida por mu)
+	dedupWindow time.Duration // 0 = desactivada
+	last        *LogMessage   // Último mensaje enviado
+	lastAt      time.Time     // Momento del último mensaje enviado, inicio de la ventana
+	repeats     int           // Repeticiones suprimidas desde lastAt
 }
 
-// NewWebSocketLogSender crea una nueva instancia del sender de logs por WebSocket
-func NewWebSocketLogSender(ctx context.Context, wsURL string, agentID string, agentName string) *WebSocketLogSender {
+// NewWebSocketLogSender crea una nueva instancia del sender de logs por WebSocket.
+// Con opts.DedupWindow > 0, los mensajes idénticos consecutivos dentro de la ventana se colapsan
+// en un único resumen "último mensaje repetido N veces".
+//
+// Las entradas de log del propio sender llevan el campo sender=websocket_logs y no deben
+// reenviarse por él (ver IsOwnEntry): mu se mantiene tomado mientras se escribe.
+func NewWebSocketLogSender(ctx context.Context, wsURL string, agentID string, agentName string, opts WebSocketLogOptions) *WebSocketLogSender {
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = defaultWSPingInterval
+	}
+	if opts.PongTimeout <= 0 {
+		opts.PongTimeout = defaultWSPongTimeout
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = defaultWSWriteTimeout
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	s := &WebSocketLogSender{
 		wsURL:             wsURL,
-		log:               logrus.WithField("sender", "websocket_logs"),
+		log:               logrus.WithField("sender", wsLogSenderName),
 		agentID:           agentID,
 		agentName:         agentName,
 		reconnectInterval: 5 * time.Second, // Intentar reconectar cada 5 segundos
+		pingInterval:      opts.PingInterval,
+		pongTimeout:       opts.PongTimeout,
+		writeTimeout:      opts.WriteTimeout,
 		ctx:               ctx,
 		cancel:            cancel,
+		dedupWindow:       opts.DedupWindow,
 	}
 	go s.connectLoop() // Iniciar bucle de conexión en goroutine separada
 	return s
 }
 
+// wsLogSenderName es el valor del campo "sender" en las entradas de log del propio sender
+const wsLogSenderName = "websocket_logs"
+
+// IsOwnEntry indica si una entrada de log la emitió el propio sender. Un hook que reenvíe los logs
+// por WebSocket debe descartarlas para no volver a entrar en SendLog.
+func IsOwnEntry(entry *logrus.Entry) bool {
+	name, _ := entry.Data["sender"].(string)
+	return name == wsLogSenderName
+}
+
 // connectLoop intenta establecer y mantener la conexión WebSocket
 func (s *WebSocketLogSender) connectLoop() {
 	ticker := time.NewTicker(s.reconnectInterval)
@@ -59,25 +116,27 @@ func (s *WebSocketLogSender) connectLoop() {
 		select {
 		case <-s.ctx.Done():
 			s.log.Info("Deteniendo el bucle de conexión WebSocket.")
-			s.disconnect()
+			s.disconnect(nil)
 			return
 		case <-ticker.C:
-			if s.conn == nil {
+			if !s.connected() {
 				s.connect()
 			}
+			s.flushExpiredRepeats()
 		}
 	}
 }
 
-// connect establece la conexión WebSocket
-func (s *WebSocketLogSender) connect() {
+// connected indica si hay una conexión abierta
+func (s *WebSocketLogSender) connected() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.conn != nil
+}
 
-	if s.conn != nil {
-		return // Ya conectado
-	}
-
+// connect establece la conexión WebSocket. El dial se hace sin mu tomado para que SendLog
+// no quede bloqueado mientras el servidor no responde.
+func (s *WebSocketLogSender) connect() {
 	s.log.Infof("Intentando conectar a WebSocket: %s", s.wsURL)
 	u, err := url.Parse(s.wsURL)
 	if err != nil {
@@ -85,55 +144,99 @@ func (s *WebSocketLogSender) connect() {
 		return
 	}
 
-	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = s.writeTimeout
+	c, _, err := dialer.DialContext(s.ctx, u.String(), nil)
 	if err != nil {
 		s.log.WithError(err).Warn("No se pudo conectar al servidor WebSocket. Reintentando...")
 		return
 	}
 
+	// Sin pong dentro de pingInterval+pongTimeout la lectura falla y se reconecta
+	readWindow := s.pingInterval + s.pongTimeout
+	c.SetReadDeadline(time.Now().Add(readWindow))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(readWindow))
+	})
+
+	s.mu.Lock()
+	if s.conn != nil || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		c.Close()
+		return
+	}
 	s.conn = c
+	s.mu.Unlock()
+
 	s.log.Info("Conexión WebSocket establecida exitosamente.")
-	// Goroutine para monitorear la conexión y manejar cierres
-	go s.readPump()
+	// Goroutines para monitorear la conexión y mantenerla viva
+	done := make(chan struct{})
+	go s.readPump(c, done)
+	go s.pingLoop(c, done)
 }
 
-// readPump monitorea la conexión para cierres del lado del servidor
-func (s *WebSocketLogSender) readPump() {
+// readPump monitorea la conexión para cierres del lado del servidor y pongs vencidos
+func (s *WebSocketLogSender) readPump(c *websocket.Conn, done chan struct{}) {
 	defer func() {
-		s.disconnect()
-		s.log.Warn("Conexión WebSocket cerrada o error de lectura. Intentando reconectar...")
+		close(done)
+		if s.disconnect(c) {
+			s.log.Warn("Conexión WebSocket cerrada o error de lectura. Intentando reconectar...")
+		}
 		// No se necesita llamar a connect() aquí, el connectLoop se encargará.
 	}()
 
+	for {
+		// Leer mensajes para detectar el cierre del lado del servidor y procesar los pongs.
+		// No esperamos recibir mensajes, solo que no haya errores de lectura.
+		_, _, err := c.ReadMessage()
+		if err != nil {
+			// Error de lectura (ej. conexión cerrada o sin pong a tiempo), salir del bucle.
+			if s.ctx.Err() == nil && websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				s.log.WithError(err).Error("Error de lectura inesperado en WebSocket.")
+			}
+			return
+		}
+	}
+}
+
+// pingLoop envía un ping cada pingInterval hasta que readPump termine.
+// WriteControl puede llamarse a la vez que las demás escrituras, así que no necesita mu.
+func (s *WebSocketLogSender) pingLoop(c *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
-		case <-s.ctx.Done():
-			return // Contexto cancelado, salir
-		default:
-			// Leer mensajes para detectar el cierre del lado del servidor.
-			// No esperamos recibir mensajes, solo que no haya errores de lectura.
-			_, _, err := s.conn.ReadMessage()
-			if err != nil {
-				// Error de lectura (ej. conexión cerrada), salir del bucle.
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					s.log.WithError(err).Error("Error de lectura inesperado en WebSocket.")
-				}
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(s.writeTimeout)); err != nil {
+				s.log.WithError(err).Warn("No se pudo enviar el ping WebSocket. Cerrando la conexión.")
+				c.Close() // readPump detecta el cierre y libera la conexión
 				return
 			}
 		}
 	}
 }
 
-// disconnect cierra la conexión WebSocket si está abierta
-func (s *WebSocketLogSender) disconnect() {
+// disconnect cierra la conexión WebSocket si está abierta. Con only distinto de nil, solo la
+// cierra si sigue siendo esa conexión. Devuelve si cerró alguna.
+func (s *WebSocketLogSender) disconnect(only *websocket.Conn) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if s.conn != nil {
+	closed := s.conn != nil && (only == nil || s.conn == only)
+	if closed {
 		s.conn.Close()
 		s.conn = nil
+	}
+	s.mu.Unlock()
+
+	if only != nil {
+		only.Close()
+	}
+	if closed {
 		s.log.Info("Conexión WebSocket cerrada.")
 	}
+	return closed
 }
 
 // SendLog envía un mensaje de log a través del WebSocket
@@ -141,19 +244,64 @@ func (s *WebSocketLogSender) SendLog(service, message, level string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.conn == nil {
-		s.log.Debug("No hay conexión WebSocket para enviar log.")
-		return
+	now := time.Now()
+	if s.dedupWindow > 0 {
+		if s.last != nil && s.last.Service == service && s.last.Level == level && s.last.Message == message &&
+			now.Sub(s.lastAt) < s.dedupWindow {
+			s.repeats++
+			return
+		}
+		s.flushRepeatsLocked()
 	}
 
 	logMsg := LogMessage{
 		AgentID:   s.agentID,
 		AgentName: s.agentName,
-		Timestamp: time.Now().Unix(),
+		Timestamp: now.Unix(),
 		Service:   service,
 		Message:   message,
 		Level:     level,
 	}
+	s.writeLocked(logMsg)
+
+	if s.dedupWindow > 0 {
+		s.last = &logMsg
+		s.lastAt = now
+	}
+}
+
+// flushRepeatsLocked envía el resumen de repeticiones suprimidas del último mensaje, si las hay.
+// Debe llamarse con mu tomado.
+func (s *WebSocketLogSender) flushRepeatsLocked() {
+	if s.last == nil || s.repeats == 0 {
+		return
+	}
+	summary := *s.last
+	summary.Timestamp = time.Now().Unix()
+	summary.Message = fmt.Sprintf("último mensaje repetido %d veces", s.repeats)
+	summary.RepeatCount = s.repeats
+	s.repeats = 0
+	s.last = nil
+	s.writeLocked(summary)
+}
+
+// flushExpiredRepeats emite el resumen pendiente cuando la ventana ha vencido sin
+// que llegue otro mensaje, para que las repeticiones no queden retenidas indefinidamente.
+func (s *WebSocketLogSender) flushExpiredRepeats() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dedupWindow > 0 && s.repeats > 0 && time.Since(s.lastAt) >= s.dedupWindow {
+		s.flushRepeatsLocked()
+	}
+}
+
+// writeLocked serializa y escribe un mensaje en la conexión. Debe llamarse con mu tomado.
+func (s *WebSocketLogSender) writeLocked(logMsg LogMessage) {
+	if s.conn == nil {
+		s.log.Debug("No hay conexión WebSocket para enviar log.")
+		return
+	}
 
 	data, err := json.Marshal(logMsg)
 	if err != nil {
@@ -161,22 +309,29 @@ func (s *WebSocketLogSender) SendLog(service, message, level string) {
 		return
 	}
 
+	s.conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
 	err = s.conn.WriteMessage(websocket.TextMessage, data)
 	if err != nil {
 		s.log.WithError(err).Error("Error al enviar mensaje de log por WebSocket. Marcando conexión para reconexión.")
-		s.disconnect() // Cerrar la conexión, el bucle de conexión intentará reconectar
+		// Cerrar la conexión aquí (mu ya está tomado); el bucle de conexión intentará reconectar
+		s.conn.Close()
+		s.conn = nil
 	} else {
 		s.log.WithFields(logrus.Fields{
-			"service": service,
-			"level":   level,
-			"message": message,
+			"service": logMsg.Service,
+			"level":   logMsg.Level,
+			"message": logMsg.Message,
 		}).Debug("Log enviado por WebSocket.")
 	}
 }
 
 // Close cierra el sender y la conexión WebSocket
 func (s *WebSocketLogSender) Close() {
+	s.mu.Lock()
+	s.flushRepeatsLocked() // No perder el resumen de repeticiones pendiente
+	s.mu.Unlock()
+
 	s.cancel() // Cancela el contexto para detener el connectLoop
-	s.disconnect()
+	s.disconnect(nil)
 	s.log.Info("Sender de logs WebSocket cerrado.")
 }
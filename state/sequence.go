@@ -0,0 +1,79 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// sequenceState es el contenido persistido en el archivo de estado
+type sequenceState struct {
+	Sequence uint64 `json:"sequence"`
+}
+
+// Sequence es un contador monótono persistido en disco para que no se reinicie
+// entre ejecuciones del agente. Es independiente del reloj del sistema.
+type Sequence struct {
+	mu    sync.Mutex
+	path  string
+	value uint64
+}
+
+// LoadSequence carga el contador desde el archivo de estado.
+// Si el archivo no existe, el contador empieza en cero.
+func LoadSequence(path string) (*Sequence, error) {
+	s := &Sequence{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("error al leer el archivo de estado %s: %w", path, err)
+	}
+
+	var st sequenceState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("error al parsear el archivo de estado %s: %w", path, err)
+	}
+	s.value = st.Sequence
+	return s, nil
+}
+
+// Next incrementa el contador, lo persiste y devuelve el nuevo valor.
+// Si la persistencia falla, el valor en memoria sigue siendo válido y se devuelve junto al error.
+func (s *Sequence) Next() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.value++
+	return s.value, s.save()
+}
+
+// save escribe el estado de forma atómica (archivo temporal + rename)
+func (s *Sequence) save() error {
+	data, err := json.Marshal(sequenceState{Sequence: s.value})
+	if err != nil {
+		return fmt.Errorf("error al serializar el estado: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".state-*")
+	if err != nil {
+		return fmt.Errorf("error al crear archivo temporal de estado: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op si el rename tuvo éxito
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error al escribir el archivo de estado: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error al cerrar el archivo de estado: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("error al reemplazar el archivo de estado %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -1,12 +1,21 @@
 package utils
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
+	"mime"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/gorilla/websocket"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 var upgrader = websocket.Upgrader{
@@ -14,34 +23,172 @@ var upgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 }
 
-func Server() {
+// ServerOptions configura el servidor de pruebas
+type ServerOptions struct {
+	Addr     string // Dirección de escucha, ej. ":4003"
+	CertFile string // Certificado y clave para servir HTTPS; vacíos para HTTP
+	KeyFile  string
 
-	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+	// OnListen, si no es nil, recibe la dirección real de escucha antes de atender peticiones
+	// (útil con el puerto 0, ej. "127.0.0.1:0")
+	OnListen func(addr string)
+}
+
+// reportSummary es el eco de un reporte recibido, para comprobar qué envía el agente
+type reportSummary struct {
+	AgentID    string   `json:"agent_id"`
+	AgentName  string   `json:"agent_name"`
+	ReportID   string   `json:"report_id,omitempty"`
+	Sequence   uint64   `json:"sequence,omitempty"`
+	Collectors []string `json:"collectors"` // Secciones <colector>_metrics presentes en el reporte
+}
+
+// parseReport decodifica el reporte según su Content-Type: JSON (por defecto), MessagePack o
+// line protocol de InfluxDB
+func parseReport(contentType string, body []byte) (map[string]interface{}, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var metrics map[string]interface{}
+	switch mediaType {
+	case "", "application/json":
+		if err := json.Unmarshal(body, &metrics); err != nil {
+			return nil, fmt.Errorf("Error al parsear JSON: %w", err)
+		}
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		dec := msgpack.NewDecoder(bytes.NewReader(body))
+		dec.UseLooseInterfaceDecoding(true) // Enteros como int64/uint64, como espera summarize
+		if err := dec.Decode(&metrics); err != nil {
+			return nil, fmt.Errorf("Error al parsear MessagePack: %w", err)
+		}
+	case "text/plain":
+		return parseLineProtocol(body)
+	default:
+		return nil, fmt.Errorf("Content-Type no soportado: %s", contentType)
+	}
+	return metrics, nil
+}
+
+// parseLineProtocol convierte las líneas en un mapa con la forma del reporte JSON: agent_id y
+// agent_name salen de los tags y cada measurement es una sección <colector>_metrics con sus
+// campos sin interpretar
+func parseLineProtocol(body []byte) (map[string]interface{}, error) {
+	metrics := make(map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := splitUnescaped(line, ' ')
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("Línea de line protocol inválida: %s", line)
+		}
+		series := splitUnescaped(parts[0], ',')
+		for _, tag := range series[1:] {
+			if key, value, ok := strings.Cut(tag, "="); ok {
+				metrics[key] = unescape(value)
+			}
+		}
+		fields := make(map[string]interface{})
+		for _, field := range splitUnescaped(parts[1], ',') {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				fields[unescape(key)] = value
+			}
+		}
+		metrics[unescape(series[0])+"_metrics"] = fields
+	}
+	return metrics, nil
+}
+
+// splitUnescaped separa s por sep ignorando los separadores escapados con \ o entre comillas
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape quita las barras de escape del line protocol
+func unescape(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=").Replace(s)
+}
+
+// summarize extrae del reporte el agente y los colectores presentes
+func summarize(metrics map[string]interface{}) reportSummary {
+	summary := reportSummary{Collectors: []string{}}
+	summary.AgentID, _ = metrics["agent_id"].(string)
+	summary.AgentName, _ = metrics["agent_name"].(string)
+	summary.ReportID, _ = metrics["report_id"].(string)
+	switch seq := metrics["sequence"].(type) { // float64 en JSON; entero en MessagePack
+	case float64:
+		summary.Sequence = uint64(seq)
+	case uint64:
+		summary.Sequence = seq
+	case int64:
+		summary.Sequence = uint64(seq)
+	}
+	for key, value := range metrics {
+		if name, ok := strings.CutSuffix(key, "_metrics"); ok && value != nil {
+			summary.Collectors = append(summary.Collectors, name)
+		}
+	}
+	sort.Strings(summary.Collectors)
+	return summary
+}
+
+// Server inicia el servidor de pruebas, que recibe reportes en /metrics y logs en /ws/logs.
+// Es bloqueante y termina el proceso si no puede escuchar.
+func Server(opts ServerOptions) {
+	if opts.Addr == "" {
+		opts.Addr = ":4003"
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		log.Fatal("Se requieren tanto el certificado como la clave para servir HTTPS")
+	}
+
+	// Mux propio en lugar de http.DefaultServeMux, para poder iniciar más de un servidor
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
 			return
 		}
 
-		body, err := io.ReadAll(r.Body)
+		reader, err := decodeBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer reader.Close()
+
+		body, err := io.ReadAll(reader)
 		if err != nil {
 			http.Error(w, "Error al leer el cuerpo de la solicitud", http.StatusInternalServerError)
 			return
 		}
 
-		var metrics map[string]interface{}
-		err = json.Unmarshal(body, &metrics)
+		metrics, err := parseReport(r.Header.Get("Content-Type"), body)
 		if err != nil {
-			http.Error(w, "Error al parsear JSON", http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		log.Printf("Métricas recibidas: %+v", metrics)
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("Métricas recibidas OK"))
+		summary := summarize(metrics)
+		log.Printf("Métricas recibidas de %s (%s), colectores %v: %+v", summary.AgentName, summary.AgentID, summary.Collectors, metrics)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summary)
 	})
 
 	// Adding websocket endpoint
-	http.HandleFunc("/ws/logs", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/ws/logs", func(w http.ResponseWriter, r *http.Request) {
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Println("Error al actualizar la conexión WebSocket:", err)
@@ -58,6 +205,40 @@ func Server() {
 		}
 	})
 
-	log.Println("Server started on :4003")
-	log.Fatal(http.ListenAndServe(":4003", nil))
+	ln, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		log.Fatalf("No se pudo escuchar en %s: %v", opts.Addr, err)
+	}
+	if opts.OnListen != nil {
+		opts.OnListen(ln.Addr().String())
+	}
+	srv := &http.Server{Handler: mux}
+	if opts.CertFile != "" {
+		log.Printf("Server started on %s (HTTPS)", ln.Addr())
+		log.Fatal(srv.ServeTLS(ln, opts.CertFile, opts.KeyFile))
+	}
+	log.Printf("Server started on %s", ln.Addr())
+	log.Fatal(srv.Serve(ln))
+}
+
+// decodeBody devuelve un lector del cuerpo descomprimido según Content-Encoding (gzip o zstd)
+func decodeBody(r *http.Request) (io.ReadCloser, error) {
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return r.Body, nil
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("cuerpo gzip inválido: %w", err)
+		}
+		return gz, nil
+	case "zstd":
+		zr, err := zstd.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("cuerpo zstd inválido: %w", err)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("Content-Encoding no soportado: %s", encoding)
+	}
 }
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/klauspost/compress/zstd"
+)
+
+const sampleReport = `{"agent_id":"6b1d0c9e-3f52-4b0e-9d7a-2f4c1e8a5b10","agent_name":"test",` +
+	`"system_metrics":{"cpu_percent":12.5},"mysql_metrics":{"threads_connected":4}}`
+
+func gzipBody(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBody(t *testing.T, data []byte) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	return enc.EncodeAll(data, nil)
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"sin compresión", "", []byte(sampleReport)},
+		{"identity", "identity", []byte(sampleReport)},
+		{"gzip", "gzip", gzipBody(t, []byte(sampleReport))},
+		{"zstd", "zstd", zstdBody(t, []byte(sampleReport))},
+		{"mayúsculas y espacios", " GZIP ", gzipBody(t, []byte(sampleReport))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Encoding", tt.encoding)
+
+			reader, err := decodeBody(req)
+			if err != nil {
+				t.Fatalf("decodeBody: %v", err)
+			}
+			defer reader.Close()
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll: %v", err)
+			}
+
+			var metrics map[string]interface{}
+			if err := json.Unmarshal(body, &metrics); err != nil {
+				t.Fatalf("el cuerpo descomprimido no es JSON válido: %v", err)
+			}
+			if metrics["agent_id"] != "6b1d0c9e-3f52-4b0e-9d7a-2f4c1e8a5b10" || metrics["system_metrics"] == nil {
+				t.Errorf("reporte mal decodificado: %v", metrics)
+			}
+		})
+	}
+}
+
+func TestDecodeBodyErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"codificación no soportada", "br", []byte(sampleReport)},
+		{"gzip inválido", "gzip", []byte(sampleReport)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Encoding", tt.encoding)
+			if _, err := decodeBody(req); err == nil {
+				t.Error("decodeBody no devolvió error")
+			}
+		})
+	}
+}
+
+// startServer inicia Server en un puerto libre y devuelve la dirección de escucha
+func startServer(t *testing.T) string {
+	t.Helper()
+	addrCh := make(chan string, 1)
+	// Server no se detiene; la goroutine termina con el proceso de test
+	go Server(ServerOptions{Addr: "127.0.0.1:0", OnListen: func(addr string) { addrCh <- addr }})
+	return <-addrCh
+}
+
+func TestServerAcceptsReport(t *testing.T) {
+	addr := startServer(t)
+
+	tests := []struct {
+		name     string
+		body     []byte
+		encoding string
+	}{
+		{"json", []byte(sampleReport), ""},
+		{"json gzip", gzipBody(t, []byte(sampleReport)), "gzip"},
+	}
+	want := reportSummary{
+		AgentID:    "6b1d0c9e-3f52-4b0e-9d7a-2f4c1e8a5b10",
+		AgentName:  "test",
+		Collectors: []string{"mysql", "system"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://"+addr+"/metrics", bytes.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST /metrics: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, se esperaba 200", resp.StatusCode)
+			}
+
+			var got reportSummary
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("respuesta inválida: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("resumen = %+v, se esperaba %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestServerRejectsInvalidReport(t *testing.T) {
+	addr := startServer(t)
+
+	resp, err := http.Post("http://"+addr+"/metrics", "application/json", strings.NewReader("{no es json"))
+	if err != nil {
+		t.Fatalf("POST /metrics: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, se esperaba 400", resp.StatusCode)
+	}
+
+	resp, err = http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, se esperaba 405", resp.StatusCode)
+	}
+}
+
+// syncBuffer es un bytes.Buffer seguro para escribir desde los handlers del servidor
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServerReceivesLogs(t *testing.T) {
+	var out syncBuffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+	addr := startServer(t)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/ws/logs", nil)
+	if err != nil {
+		t.Fatalf("Dial /ws/logs: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"msg":"hola"}`)); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), `Mensaje recibido: {"msg":"hola"}`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("el servidor no registró el mensaje; salida: %s", out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,30 @@
+package version
+
+import "runtime"
+
+// Valores inyectados en tiempo de compilación con -ldflags, por ejemplo:
+//
+//	go build -ldflags "-X github.com/atrox39/logtick/version.Version=v1.2.0 -X github.com/atrox39/logtick/version.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown" // Fecha de compilación (RFC 3339)
+)
+
+// Info describe la compilación del agente
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get devuelve la información de compilación del binario en ejecución
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -1,3 +1,17 @@
+// Los campos de memoria llevan la unidad configurada en el nombre (memory_used_bytes, memory_used_mb, ...)
+const memoryUnits = [['bytes', 1024 * 1024], ['kb', 1024], ['mb', 1], ['gb', 1 / 1024]];
+
+// Muestra la memoria en MB sea cual sea la unidad reportada
+function formatMemory(systemMetrics, kind) {
+  for (const [suffix, perMB] of memoryUnits) {
+    const value = systemMetrics[`memory_${kind}_${suffix}`];
+    if (value !== undefined) {
+      return `${(value / perMB).toFixed(0)} MB`;
+    }
+  }
+  return '-';
+}
+
 async function fetchMetrics() {
   try {
       const response = await fetch('/api/current_metrics'); // Endpoint en Go
@@ -6,8 +20,8 @@ async function fetchMetrics() {
       document.getElementById('display-agent-id').textContent = agentReport.agent_id;
       document.getElementById('display-agent-name').textContent = agentReport.agent_name;
       document.getElementById('display-cpu-percent').textContent = `${agentReport.system_metrics.cpu_percent.toFixed(2)}%`; // Formatear CPU a 2 decimales
-      document.getElementById('display-memory-used').textContent = `${agentReport.system_metrics.memory_used_mb} MB`;
-      document.getElementById('display-memory-free').textContent = `${agentReport.system_metrics.memory_free_mb} MB`;
+      document.getElementById('display-memory-used').textContent = formatMemory(agentReport.system_metrics, 'used');
+      document.getElementById('display-memory-free').textContent = formatMemory(agentReport.system_metrics, 'free');
 
       // Formatear el timestamp a un formato legible
       const date = new Date(agentReport.timestamp * 1000); // Multiplicar por 1000 porque el timestamp de Go es en segundos
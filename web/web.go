@@ -0,0 +1,9 @@
+package web
+
+import "embed"
+
+// Assets contiene la UI (index.html y sus recursos estáticos) incluida en el binario, para que el
+// agente no dependa del directorio desde el que se ejecuta
+//
+//go:embed index.html app.js style.css
+var Assets embed.FS